@@ -16,7 +16,7 @@ func main() {
 	defineAst(os.Args[1], "expression.go", "Expr", []string{
 		"Assign : name *scanner.Token, value Expr",
 		"Binary : left Expr, operator *scanner.Token, right Expr",
-		"Call : callee Expr, paren *scanner.Token, arguments []Expr",
+		"Call : callee Expr, paren *scanner.Token, arguments []Expr, isNew bool",
 		"GetMethod : object Expr, name *scanner.Token",
 		"GetField : object Expr, name *scanner.Token",
 		"Set : object Expr, name *scanner.Token, value Expr",
@@ -27,20 +27,47 @@ func main() {
 		"Logical : left Expr, operator *scanner.Token, right Expr",
 		"Unary : operator *scanner.Token, right Expr",
 		"Variable : name *scanner.Token, t references.FunctionType",
+		"ListLiteral : bracket *scanner.Token, elements []Expr",
+		"MapLiteral : brace *scanner.Token, keys []Expr, values []Expr",
+		"TupleLiteral : paren *scanner.Token, elements []Expr",
+		"RangeLiteral : start Expr, operator *scanner.Token, end Expr, step Expr",
+		"Index : object Expr, bracket *scanner.Token, index Expr",
+		"SetIndex : object Expr, bracket *scanner.Token, index Expr, value Expr",
+		"Slice : object Expr, bracket *scanner.Token, start Expr, end Expr",
 	})
 
 	defineAst(os.Args[1], "statement.go", "Stmt", []string{
-		"Block : statements []Stmt, isLoopIncrementer bool",
+		"Block : statements []Stmt",
 		"Expression : expression Expr",
-		"Function : name *scanner.Token, params []*scanner.Token, body []Stmt, isStatic bool",
+		"Function : name *scanner.Token, params []*scanner.Token, body []Stmt, isStatic bool, isAbstract bool",
 		"IfCmd : condition Expr, thenBranch Stmt, elseBranch Stmt",
 		"Print : expression Expr",
 		"ReturnCmd : keyword *scanner.Token, value Expr",
 		"VarCmd : name *scanner.Token, initializer Expr",
-		"WhileLoop : condition Expr, body Stmt",
-		"BreakCmd : keyword *scanner.Token, envDepth int",
-		"ContinueCmd : keyword *scanner.Token, envDepth int",
-		"Class : name *scanner.Token, superclass *Variable, methods []*Function, fields []*VarCmd",
+		"WhileLoop : condition Expr, body Stmt, increment Expr, label *scanner.Token",
+		"DoWhile : body Stmt, keyword *scanner.Token, condition Expr, until bool, label *scanner.Token",
+		"ForIn : keyword *scanner.Token, variables []*scanner.Token, iterable Expr, body Stmt, label *scanner.Token",
+		"BreakCmd : keyword *scanner.Token, label *scanner.Token",
+		"ContinueCmd : keyword *scanner.Token, label *scanner.Token",
+		"Class : name *scanner.Token, superclass *Variable, traits []*Variable, interfaces []*Variable, methods []*Function, fields []*VarCmd, uses []*TraitUse, isAbstract bool",
+		"InterfaceCmd : name *scanner.Token, methods []*Function",
+		"Match : keyword *scanner.Token, subject Expr, cases []*scanner.Token, patterns [][]Pattern, guards []Expr, bodies []Stmt",
+		"ImportCmd : keyword *scanner.Token, path *scanner.Token, name *scanner.Token",
+		"Export : keyword *scanner.Token, declaration Stmt",
+		"DeferCmd : keyword *scanner.Token, expression Expr",
+		"Throw : keyword *scanner.Token, value Expr",
+		"Try : keyword *scanner.Token, body []Stmt, catchName *scanner.Token, catchBody []Stmt, hasFinally bool, finallyBody []Stmt",
+		"Trait : name *scanner.Token, methods []*Function",
+	})
+
+	defineAst(os.Args[1], "pattern.go", "Pattern", []string{
+		"MatchLiteral : token *scanner.Token, value interface{}",
+		"MatchRange : operator *scanner.Token, low float64, high float64",
+		"MatchWildcard : token *scanner.Token",
+		"MatchBinding : name *scanner.Token",
+		"MatchClass : class *Variable, fields []Pattern",
+		"MatchList : bracket *scanner.Token, elements []Pattern",
+		"MatchTuple : paren *scanner.Token, elements []Pattern",
 	})
 }
 
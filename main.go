@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"golox/loxerror"
 	"golox/scanner"
@@ -12,15 +13,25 @@ import (
 	"strings"
 )
 
-var interpreter = syntax.NewInterpreter()
+var interpreter *syntax.Interpreter
 
 func main() {
-	length := len(os.Args)
-	if length > 2 {
-		fmt.Printf("Usage: golox [script]")
-		os.Exit(64)
-	} else if length == 2 {
-		runFile(os.Args[1])
+	sandbox := flag.Bool("sandbox", false, "run without access to files, stdin, the environment or other processes")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: golox [--sandbox] [script [args...]]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *sandbox {
+		interpreter = syntax.NewInterpreter()
+	} else {
+		interpreter = syntax.NewInterpreter(syntax.WithCapabilities(syntax.AllCapabilities()...))
+	}
+
+	if args := flag.Args(); len(args) >= 1 {
+		interpreter.SetArgs(args[1:])
+		runFile(args[0])
 	} else {
 		runPrompt()
 	}
@@ -43,6 +54,7 @@ func runFile(path string) {
 		os.Exit(64)
 	}
 
+	interpreter.SetScriptPath(path)
 	run(string(data))
 
 	if loxerror.HadError() {
@@ -85,6 +97,10 @@ func run(source string) {
 
 	defer func() {
 		if r := recover(); r != nil {
+			if exit, ok := r.(*syntax.Exit); ok {
+				os.Exit(exit.Code)
+			}
+
 			fmt.Printf("%v\n", r)
 			/*if strings.Contains(err.Error(), "stack overflow") {
 				fmt.Println("[Stack Overflow] - infinite loop?")
@@ -96,7 +112,7 @@ func run(source string) {
 	scanner := scanner.NewScanner(source)
 	tokens := scanner.ScanTokens()
 
-	parser := syntax.NewAstParser(tokens)
+	parser := syntax.NewAstParser(tokens, interpreter)
 	statements := parser.Parse()
 
 	if loxerror.HadError() {
@@ -6,4 +6,5 @@ const (
 	NoneClass ClassType = iota
 	KlassClass
 	SubClass
+	TraitClass
 )
@@ -8,6 +8,8 @@ const (
 	RightParen
 	LeftBrace
 	RightBrace
+	LeftBracket
+	RightBracket
 	Comma
 	Dot
 	Minus
@@ -16,6 +18,7 @@ const (
 	Modulo
 	Slash
 	Star
+	Colon
 
 	// One or two character tokens
 	Bang
@@ -26,6 +29,9 @@ const (
 	GreaterEqual
 	Less
 	LessEqual
+	DotDot
+	DotDotLess
+	Arrow
 
 	// Literals
 	Identifier
@@ -51,8 +57,25 @@ const (
 	True
 	Var
 	While
+	Do
 	Break
 	Continue
+	Abstract
+	Interface
+	Implements
+	Is
+	Trait
+	With
+	In
+	Match
+	Case
+	Throw
+	Try
+	Catch
+	Finally
+	Defer
+	Import
+	Export
 	Increment
 	Decrement
 	IncrementOne
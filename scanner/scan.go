@@ -7,26 +7,44 @@ import (
 )
 
 var keywords = map[string]references.TokenType{
-	"and":      references.And,
-	"new":      references.New,
-	"static":   references.Static,
-	"class":    references.Class,
-	"else":     references.Else,
-	"false":    references.False,
-	"for":      references.For,
-	"fun":      references.Fun,
-	"if":       references.If,
-	"nil":      references.Nil,
-	"or":       references.Or,
-	"print":    references.Print,
-	"return":   references.Return,
-	"super":    references.Super,
-	"this":     references.This,
-	"true":     references.True,
-	"var":      references.Var,
-	"while":    references.While,
-	"continue": references.Continue,
-	"break":    references.Break,
+	"and":        references.And,
+	"new":        references.New,
+	"static":     references.Static,
+	"class":      references.Class,
+	"else":       references.Else,
+	"false":      references.False,
+	"for":        references.For,
+	"fun":        references.Fun,
+	"if":         references.If,
+	"nil":        references.Nil,
+	"or":         references.Or,
+	"print":      references.Print,
+	"return":     references.Return,
+	"super":      references.Super,
+	"this":       references.This,
+	"true":       references.True,
+	"var":        references.Var,
+	"while":      references.While,
+	"do":         references.Do,
+	"continue":   references.Continue,
+	"break":      references.Break,
+	"abstract":   references.Abstract,
+	"interface":  references.Interface,
+	"implements": references.Implements,
+	"is":         references.Is,
+	"instanceof": references.Is,
+	"trait":      references.Trait,
+	"with":       references.With,
+	"in":         references.In,
+	"match":      references.Match,
+	"case":       references.Case,
+	"throw":      references.Throw,
+	"try":        references.Try,
+	"catch":      references.Catch,
+	"finally":    references.Finally,
+	"defer":      references.Defer,
+	"import":     references.Import,
+	"export":     references.Export,
 }
 
 type Scanner struct {
@@ -75,11 +93,27 @@ func (scanner *Scanner) scanToken() {
 	case '}':
 		scanner.addToken(references.RightBrace)
 		break
+	case '[':
+		scanner.addToken(references.LeftBracket)
+		break
+	case ']':
+		scanner.addToken(references.RightBracket)
+		break
+	case ':':
+		scanner.addToken(references.Colon)
+		break
 	case ',':
 		scanner.addToken(references.Comma)
 		break
 	case '.':
-		scanner.addToken(references.Dot)
+		token := references.Dot
+		if scanner.match('.') {
+			token = references.DotDot
+			if scanner.match('<') {
+				token = references.DotDotLess
+			}
+		}
+		scanner.addToken(token)
 		break
 	case '%':
 		scanner.addToken(references.Modulo)
@@ -129,6 +163,8 @@ func (scanner *Scanner) scanToken() {
 		token := references.Equal
 		if scanner.match('=') {
 			token = references.EqualEqual
+		} else if scanner.match('>') {
+			token = references.Arrow
 		}
 		scanner.addToken(token)
 		break
@@ -0,0 +1,79 @@
+package syntax
+
+import (
+	"fmt"
+	"golox/scanner"
+)
+
+// Capability grants scripts access to part of the host through natives.
+type Capability int
+
+const (
+	FS_READ Capability = iota
+	FS_WRITE
+	STDIN
+	ENV
+	PROCESS
+	IMPORT
+)
+
+// AllCapabilities lists every capability, for trusted scripts.
+func AllCapabilities() []Capability {
+	return []Capability{FS_READ, FS_WRITE, STDIN, ENV, PROCESS, IMPORT}
+}
+
+// Option configures an interpreter created by NewInterpreter.
+type Option func(interpreter *Interpreter)
+
+// WithCapabilities grants the given capabilities.
+func WithCapabilities(capabilities ...Capability) Option {
+	return func(interpreter *Interpreter) {
+		for _, capability := range capabilities {
+			interpreter.capabilities[capability] = true
+		}
+	}
+}
+
+// hostNative is a native of a module that reaches outside the interpreter,
+// paired with the capability it needs. Host modules are built only from
+// hostNatives, so every native added to them has to say what it needs.
+type hostNative struct {
+	native     *NativeFunction
+	capability Capability
+	restricted bool
+}
+
+// requires declares that native is only registered with capability.
+func requires(capability Capability, native *NativeFunction) hostNative {
+	return hostNative{native: native, capability: capability, restricted: true}
+}
+
+// always declares that native needs no capability.
+func always(native *NativeFunction) hostNative {
+	return hostNative{native: native}
+}
+
+// newHostModule builds a native module with only the natives the interpreter
+// has capabilities for. The rest are withheld, so untrusted scripts can't
+// reach them at all.
+func (interpreter *Interpreter) newHostModule(name string, natives ...hostNative) *LoxModule {
+	module := NewNativeModule(name)
+	module.withheld = make(map[string]bool)
+
+	for _, host := range natives {
+		if host.restricted && !interpreter.capabilities[host.capability] {
+			module.withheld[host.native.nativeName] = true
+			continue
+		}
+
+		module.define(host.native.nativeName, host.native)
+	}
+
+	return module
+}
+
+func (interpreter *Interpreter) requireCapability(token *scanner.Token, capability Capability, what string) {
+	if !interpreter.capabilities[capability] {
+		throwRuntimeError(token, fmt.Sprintf("%s is not allowed in this interpreter.", what))
+	}
+}
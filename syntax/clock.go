@@ -16,7 +16,7 @@ func (clock *Clock) arity() int {
 }
 
 func (clock *Clock) call(interpreter *Interpreter, arguments []interface{}) interface{} {
-	return time.Now().UnixNano() / int64(time.Millisecond) / int64(time.Second)
+	return float64(time.Now().UnixNano()) / float64(time.Second)
 }
 
 func (clock *Clock) callableType() references.FunctionType {
@@ -8,21 +8,17 @@ import (
 var level = -1
 
 type Environment struct {
-	enclosing  *Environment
-	exit       bool
-	continuing bool
-	values     map[string]interface{}
-	name       string
+	enclosing *Environment
+	values    map[string]interface{}
+	name      string
 }
 
 func NewEnvironment(enclosing *Environment) *Environment {
 	level++
 	return &Environment{
-		enclosing:  enclosing,
-		exit:       false,
-		continuing: false,
-		values:     make(map[string]interface{}),
-		name:       fmt.Sprintf("env: %d", level),
+		enclosing: enclosing,
+		values:    make(map[string]interface{}),
+		name:      fmt.Sprintf("env: %d", level),
 	}
 }
 
@@ -0,0 +1,205 @@
+package syntax
+
+import (
+	"fmt"
+	"golox/loxerror"
+	"golox/scanner"
+)
+
+// errorPrelude declares the classes scripts use to throw and catch errors.
+// Runtime errors raised by the interpreter are caught as RuntimeError.
+const errorPrelude = `
+class Error {
+	message = nil;
+	stack = nil;
+
+	init(message) {
+		this.message = message;
+	}
+
+	toString() {
+		return classOf(this) + ": " + this.message;
+	}
+}
+
+class RuntimeError < Error {}
+`
+
+// RuntimeError is the panic value of an error raised by the interpreter.
+type RuntimeError struct {
+	token   *scanner.Token
+	message string
+}
+
+func (err *RuntimeError) Error() string {
+	return err.message
+}
+
+// LoxException is the panic value of a thrown Lox value on its way to the
+// nearest enclosing try statement.
+type LoxException struct {
+	token   *scanner.Token
+	value   interface{}
+	message string
+	stack   []string
+}
+
+type returnValue struct {
+	value interface{}
+}
+
+type callFrame struct {
+	name string
+	line int
+}
+
+func (interpreter *Interpreter) definePrelude() {
+	statements := NewAstParser(scanner.NewScanner(errorPrelude).ScanTokens(), interpreter).Parse()
+	interpreter.builtinClasses = copyNames(interpreter.declaredClasses)
+
+	NewResolver(interpreter).Resolve(statements)
+	interpreter.Interpret(statements)
+
+	interpreter.errorClass = interpreter.globals.values["Error"].(*LoxClass)
+	interpreter.runtimeErrorClass = interpreter.globals.values["RuntimeError"].(*LoxClass)
+}
+
+func (interpreter *Interpreter) visitThrowStmt(stmt *Throw) interface{} {
+	value := interpreter.evaluate(stmt.value)
+	panic(interpreter.newException(stmt.keyword, value, "Uncaught "+interpreter.describeError(value)))
+}
+
+func (interpreter *Interpreter) visitTryStmt(stmt *Try) (result interface{}) {
+	env := interpreter.env
+	callToken := interpreter.callToken
+	depth := len(interpreter.frames)
+
+	if stmt.hasFinally {
+		defer func() {
+			r := recover()
+			if r != nil {
+				if ex := interpreter.exception(r); ex != nil {
+					r = ex
+				}
+				interpreter.unwind(env, callToken, depth)
+			}
+
+			// A break or continue in the finally block discards whatever
+			// was propagating out of the try.
+			if jump := interpreter.executeBlock(stmt.finallyBody, NewEnvironment(env)); jump != nil {
+				result = jump
+				return
+			}
+
+			if r != nil {
+				panic(r)
+			}
+		}()
+	}
+
+	return interpreter.executeTry(stmt, env, callToken, depth)
+}
+
+func (interpreter *Interpreter) executeTry(stmt *Try, env *Environment, callToken *scanner.Token, depth int) (jump *loopJump) {
+	if stmt.catchName != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				ex := interpreter.exception(r)
+				if ex == nil {
+					panic(r)
+				}
+				interpreter.unwind(env, callToken, depth)
+
+				catchEnv := NewEnvironment(env)
+				catchEnv.define(stmt.catchName.Lexeme, ex.value)
+				jump = interpreter.executeBlock(stmt.catchBody, catchEnv)
+			}
+		}()
+	}
+
+	return interpreter.executeBlock(stmt.body, NewEnvironment(env))
+}
+
+// unwind restores the interpreter state saved when a try statement was
+// entered, since functions exited by a panic don't restore it themselves.
+func (interpreter *Interpreter) unwind(env *Environment, callToken *scanner.Token, depth int) {
+	interpreter.env = env
+	interpreter.callToken = callToken
+	interpreter.frames = interpreter.frames[:depth]
+}
+
+// exception converts a recovered panic into the exception a script can
+// catch, or returns nil for panics that aren't exceptions.
+func (interpreter *Interpreter) exception(r interface{}) *LoxException {
+	switch err := r.(type) {
+	case *LoxException:
+		return err
+	case *RuntimeError:
+		instance := NewLoxInstance(interpreter.runtimeErrorClass)
+		instance.fields["message"] = err.message
+		return interpreter.newException(err.token, instance, err.message)
+	}
+
+	return nil
+}
+
+func (interpreter *Interpreter) newException(token *scanner.Token, value interface{}, message string) *LoxException {
+	stack := interpreter.stackTrace(token)
+
+	// An Error keeps the stack from where it was first thrown, so rethrowing
+	// it from a catch block doesn't lose the original location.
+	if instance, ok := value.(*LoxInstance); ok && instance.class.isSubtypeOf(interpreter.errorClass) {
+		if list, ok := instance.fields["stack"].(*LoxList); ok {
+			stack = stack[:0]
+			for _, line := range list.elements {
+				stack = append(stack, interpreter.stringify(line))
+			}
+		} else {
+			lines := make([]interface{}, len(stack))
+			for i, line := range stack {
+				lines[i] = line
+			}
+
+			instance.fields["stack"] = NewLoxList(lines)
+		}
+	}
+
+	return &LoxException{
+		token:   token,
+		value:   value,
+		message: message,
+		stack:   stack,
+	}
+}
+
+// stackTrace lists the active calls innermost first, each with the line
+// execution had reached in it.
+func (interpreter *Interpreter) stackTrace(token *scanner.Token) []string {
+	line := token.Line
+
+	var stack []string
+	for i := len(interpreter.frames) - 1; i >= 0; i-- {
+		stack = append(stack, fmt.Sprintf("at %s (line %d)", interpreter.frames[i].name, line))
+		line = interpreter.frames[i].line
+	}
+
+	return append(stack, fmt.Sprintf("at script (line %d)", line))
+}
+
+func (interpreter *Interpreter) describeError(value interface{}) string {
+	if instance, ok := value.(*LoxInstance); ok && instance.class.isSubtypeOf(interpreter.errorClass) {
+		return fmt.Sprintf("%s: %s", instance.class.name(), interpreter.stringify(instance.fields["message"]))
+	}
+
+	return interpreter.repr(value)
+}
+
+func (interpreter *Interpreter) report(ex *LoxException) {
+	loxerror.TokenRuntimeError(ex.token.Type, ex.token.Line, ex.token.Lexeme, ex.message, true)
+
+	if len(ex.stack) > 1 {
+		for _, line := range ex.stack {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+}
@@ -24,6 +24,13 @@ type ExprVisitor interface {
 	visitLogicalExpr(expr *Logical) interface{}
 	visitUnaryExpr(expr *Unary) interface{}
 	visitVariableExpr(expr *Variable) interface{}
+	visitListLiteralExpr(expr *ListLiteral) interface{}
+	visitMapLiteralExpr(expr *MapLiteral) interface{}
+	visitTupleLiteralExpr(expr *TupleLiteral) interface{}
+	visitRangeLiteralExpr(expr *RangeLiteral) interface{}
+	visitIndexExpr(expr *Index) interface{}
+	visitSetIndexExpr(expr *SetIndex) interface{}
+	visitSliceExpr(expr *Slice) interface{}
 }
 
 type Assign struct {
@@ -72,13 +79,15 @@ type Call struct {
 	callee    Expr
 	paren     *scanner.Token
 	arguments []Expr
+	isNew     bool
 }
 
-func NewCall(callee Expr, paren *scanner.Token, arguments []Expr) Expr {
+func NewCall(callee Expr, paren *scanner.Token, arguments []Expr, isNew bool) Expr {
 	return &Call{
 		callee:    callee,
 		paren:     paren,
 		arguments: arguments,
+		isNew:     isNew,
 	}
 }
 
@@ -287,3 +296,159 @@ func (variable *Variable) accept(visitor ExprVisitor) interface{} {
 func (variable *Variable) String() string {
 	return "Variable"
 }
+
+type ListLiteral struct {
+	bracket  *scanner.Token
+	elements []Expr
+}
+
+func NewListLiteral(bracket *scanner.Token, elements []Expr) Expr {
+	return &ListLiteral{
+		bracket:  bracket,
+		elements: elements,
+	}
+}
+
+func (listliteral *ListLiteral) accept(visitor ExprVisitor) interface{} {
+	return visitor.visitListLiteralExpr(listliteral)
+}
+
+func (listliteral *ListLiteral) String() string {
+	return "ListLiteral"
+}
+
+type MapLiteral struct {
+	brace  *scanner.Token
+	keys   []Expr
+	values []Expr
+}
+
+func NewMapLiteral(brace *scanner.Token, keys []Expr, values []Expr) Expr {
+	return &MapLiteral{
+		brace:  brace,
+		keys:   keys,
+		values: values,
+	}
+}
+
+func (mapliteral *MapLiteral) accept(visitor ExprVisitor) interface{} {
+	return visitor.visitMapLiteralExpr(mapliteral)
+}
+
+func (mapliteral *MapLiteral) String() string {
+	return "MapLiteral"
+}
+
+type TupleLiteral struct {
+	paren    *scanner.Token
+	elements []Expr
+}
+
+func NewTupleLiteral(paren *scanner.Token, elements []Expr) Expr {
+	return &TupleLiteral{
+		paren:    paren,
+		elements: elements,
+	}
+}
+
+func (tupleliteral *TupleLiteral) accept(visitor ExprVisitor) interface{} {
+	return visitor.visitTupleLiteralExpr(tupleliteral)
+}
+
+func (tupleliteral *TupleLiteral) String() string {
+	return "TupleLiteral"
+}
+
+type RangeLiteral struct {
+	start    Expr
+	operator *scanner.Token
+	end      Expr
+	step     Expr
+}
+
+func NewRangeLiteral(start Expr, operator *scanner.Token, end Expr, step Expr) Expr {
+	return &RangeLiteral{
+		start:    start,
+		operator: operator,
+		end:      end,
+		step:     step,
+	}
+}
+
+func (rangeliteral *RangeLiteral) accept(visitor ExprVisitor) interface{} {
+	return visitor.visitRangeLiteralExpr(rangeliteral)
+}
+
+func (rangeliteral *RangeLiteral) String() string {
+	return "RangeLiteral"
+}
+
+type Index struct {
+	object  Expr
+	bracket *scanner.Token
+	index   Expr
+}
+
+func NewIndex(object Expr, bracket *scanner.Token, index Expr) Expr {
+	return &Index{
+		object:  object,
+		bracket: bracket,
+		index:   index,
+	}
+}
+
+func (index *Index) accept(visitor ExprVisitor) interface{} {
+	return visitor.visitIndexExpr(index)
+}
+
+func (index *Index) String() string {
+	return "Index"
+}
+
+type SetIndex struct {
+	object  Expr
+	bracket *scanner.Token
+	index   Expr
+	value   Expr
+}
+
+func NewSetIndex(object Expr, bracket *scanner.Token, index Expr, value Expr) Expr {
+	return &SetIndex{
+		object:  object,
+		bracket: bracket,
+		index:   index,
+		value:   value,
+	}
+}
+
+func (setindex *SetIndex) accept(visitor ExprVisitor) interface{} {
+	return visitor.visitSetIndexExpr(setindex)
+}
+
+func (setindex *SetIndex) String() string {
+	return "SetIndex"
+}
+
+type Slice struct {
+	object  Expr
+	bracket *scanner.Token
+	start   Expr
+	end     Expr
+}
+
+func NewSlice(object Expr, bracket *scanner.Token, start Expr, end Expr) Expr {
+	return &Slice{
+		object:  object,
+		bracket: bracket,
+		start:   start,
+		end:     end,
+	}
+}
+
+func (slice *Slice) accept(visitor ExprVisitor) interface{} {
+	return visitor.visitSliceExpr(slice)
+}
+
+func (slice *Slice) String() string {
+	return "Slice"
+}
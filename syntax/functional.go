@@ -0,0 +1,219 @@
+package syntax
+
+// defineFunctional defines the higher-order collection natives. Each takes
+// any iterable first and calls back into Lox for every item; the ones that
+// build a sequence return a new list.
+func defineFunctional(env *Environment) {
+	env.define("map", NewNativeFunction("map", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		fn := interpreter.callbackArgument("map", arguments, 1, 1)
+
+		var results []interface{}
+		interpreter.iterate(interpreter.callToken, arguments[0], func(item interface{}) bool {
+			results = append(results, fn.call(interpreter, []interface{}{item}))
+			return true
+		})
+
+		return NewLoxList(results)
+	}))
+
+	env.define("filter", NewNativeFunction("filter", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		fn := interpreter.callbackArgument("filter", arguments, 1, 1)
+
+		var results []interface{}
+		interpreter.iterate(interpreter.callToken, arguments[0], func(item interface{}) bool {
+			if isTruthy(fn.call(interpreter, []interface{}{item})) {
+				results = append(results, item)
+			}
+			return true
+		})
+
+		return NewLoxList(results)
+	}))
+
+	env.define("reduce", NewNativeFunction("reduce", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		interpreter.checkArity("reduce", arguments, 2, 3)
+		fn := interpreter.callbackArgument("reduce", arguments, 1, 2)
+
+		// Without an initial value the first item starts the accumulator.
+		var accumulator interface{}
+		started := len(arguments) == 3
+		if started {
+			accumulator = arguments[2]
+		}
+
+		interpreter.iterate(interpreter.callToken, arguments[0], func(item interface{}) bool {
+			if !started {
+				accumulator, started = item, true
+				return true
+			}
+
+			accumulator = fn.call(interpreter, []interface{}{accumulator, item})
+			return true
+		})
+
+		if !started {
+			interpreter.nativeError("Can't reduce an empty sequence without an initial value.")
+		}
+
+		return accumulator
+	}))
+
+	env.define("each", NewNativeFunction("each", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		fn := interpreter.callbackArgument("each", arguments, 1, 1)
+
+		interpreter.iterate(interpreter.callToken, arguments[0], func(item interface{}) bool {
+			fn.call(interpreter, []interface{}{item})
+			return true
+		})
+
+		return nil
+	}))
+
+	env.define("any", NewNativeFunction("any", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		fn := interpreter.callbackArgument("any", arguments, 1, 1)
+
+		found := false
+		interpreter.iterate(interpreter.callToken, arguments[0], func(item interface{}) bool {
+			found = isTruthy(fn.call(interpreter, []interface{}{item}))
+			return !found
+		})
+
+		return found
+	}))
+
+	env.define("all", NewNativeFunction("all", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		fn := interpreter.callbackArgument("all", arguments, 1, 1)
+
+		all := true
+		interpreter.iterate(interpreter.callToken, arguments[0], func(item interface{}) bool {
+			all = isTruthy(fn.call(interpreter, []interface{}{item}))
+			return all
+		})
+
+		return all
+	}))
+
+	env.define("find", NewNativeFunction("find", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		fn := interpreter.callbackArgument("find", arguments, 1, 1)
+
+		var found interface{}
+		interpreter.iterate(interpreter.callToken, arguments[0], func(item interface{}) bool {
+			if isTruthy(fn.call(interpreter, []interface{}{item})) {
+				found = item
+				return false
+			}
+			return true
+		})
+
+		return found
+	}))
+
+	env.define("sortBy", NewNativeFunction("sortBy", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		fn := interpreter.callbackArgument("sortBy", arguments, 1, 1)
+
+		var items, keys []interface{}
+		interpreter.iterate(interpreter.callToken, arguments[0], func(item interface{}) bool {
+			items = append(items, item)
+			keys = append(keys, fn.call(interpreter, []interface{}{item}))
+			return true
+		})
+
+		// Sort the positions by key so that items and keys stay paired.
+		order := make([]interface{}, len(items))
+		for i := range order {
+			order[i] = float64(i)
+		}
+
+		sorted := sortValuesBy(order, func(i int) interface{} {
+			return keys[int(order[i].(float64))]
+		})
+
+		if !sorted {
+			interpreter.nativeError("Keys returned to 'sortBy' must be all numbers or all strings.")
+		}
+
+		results := make([]interface{}, len(items))
+		for i, position := range order {
+			results[i] = items[int(position.(float64))]
+		}
+
+		return NewLoxList(results)
+	}))
+
+	env.define("groupBy", NewNativeFunction("groupBy", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		fn := interpreter.callbackArgument("groupBy", arguments, 1, 1)
+
+		groups := NewLoxMap()
+		interpreter.iterate(interpreter.callToken, arguments[0], func(item interface{}) bool {
+			key := fn.call(interpreter, []interface{}{item})
+
+			if entry, _ := groups.find(interpreter, interpreter.callToken, key); entry != nil {
+				group := entry.value.(*LoxList)
+				group.elements = append(group.elements, item)
+			} else {
+				groups.set(interpreter, interpreter.callToken, key, NewLoxList([]interface{}{item}))
+			}
+			return true
+		})
+
+		return groups
+	}))
+
+	env.define("zip", NewNativeFunction("zip", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		interpreter.checkArity("zip", arguments, 2, 256)
+
+		// Stops at the end of the shortest sequence.
+		sequences := make([][]interface{}, len(arguments))
+		shortest := -1
+		for i, argument := range arguments {
+			interpreter.iterate(interpreter.callToken, argument, func(item interface{}) bool {
+				sequences[i] = append(sequences[i], item)
+				return true
+			})
+
+			if shortest < 0 || len(sequences[i]) < shortest {
+				shortest = len(sequences[i])
+			}
+		}
+
+		tuples := make([]interface{}, shortest)
+		for i := range tuples {
+			elements := make([]interface{}, len(sequences))
+			for j, sequence := range sequences {
+				elements[j] = sequence[i]
+			}
+			tuples[i] = NewLoxTuple(elements)
+		}
+
+		return NewLoxList(tuples)
+	}))
+
+	env.define("enumerate", NewNativeFunction("enumerate", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		interpreter.checkArity("enumerate", arguments, 1, 2)
+
+		index := 0
+		if len(arguments) == 2 {
+			index = checkInteger(interpreter.callToken, arguments[1], "Start")
+		}
+
+		var tuples []interface{}
+		interpreter.iterate(interpreter.callToken, arguments[0], func(item interface{}) bool {
+			tuples = append(tuples, NewLoxTuple([]interface{}{float64(index), item}))
+			index++
+			return true
+		})
+
+		return NewLoxList(tuples)
+	}))
+}
+
+// callbackArgument returns the function argument at index, checking that it
+// takes the number of arguments the native will pass it.
+func (interpreter *Interpreter) callbackArgument(native string, arguments []interface{}, index int, arity int) LoxCallable {
+	fn := interpreter.callableArgument(native, arguments, index)
+	if fn.arity() >= 0 && fn.arity() != arity {
+		interpreter.nativeError("Function passed to '%s' must take %d argument(s) but '%s' takes %d.", native, arity, fn.name(), fn.arity())
+	}
+
+	return fn
+}
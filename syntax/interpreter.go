@@ -1,41 +1,116 @@
 package syntax
 
 import (
+	"bufio"
 	"fmt"
-	"golox/loxerror"
 	"golox/references"
 	"golox/scanner"
+	"hash/fnv"
 	"math"
+	"math/rand"
+	"os"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
-var globals = NewEnvironment(nil)
-var locals = map[Expr]*int{}
+// loopJump is returned by a break or continue statement and passed up
+// through the enclosing statements until it reaches the loop it targets.
+type loopJump struct {
+	target     Stmt
+	isContinue bool
+}
 
 type Interpreter struct {
-	env  *Environment
-	prev *Environment
+	globals      *Environment
+	env          *Environment
+	locals       map[Expr]*int
+	loopTargets  map[Stmt]Stmt
+	callToken    *scanner.Token
+	stringifying map[interface{}]bool
+
+	frames            []callFrame
+	deferred          [][]func()
+	errorClass        *LoxClass
+	runtimeErrorClass *LoxClass
+	random            *rand.Rand
+	stdin             *bufio.Reader
+
+	module    *LoxModule
+	modules   map[string]*LoxModule
+	importing []string
+
+	capabilities map[Capability]bool
+
+	// Class and type names the parser has seen in the main script, and the
+	// classes declared by the prelude, which every file can instantiate.
+	declaredClasses map[string]bool
+	declaredTypes   map[string]bool
+	builtinClasses  map[string]bool
 }
 
-func NewInterpreter() *Interpreter {
-	globals.define("clock", NewClock())
+// NewInterpreter creates an interpreter with its own globals. Natives that
+// reach outside the interpreter are only defined for the capabilities granted
+// by the options, so by default a script can compute but not touch the host.
+func NewInterpreter(options ...Option) *Interpreter {
+	globals := NewEnvironment(nil)
+	interpreter := &Interpreter{
+		globals:      globals,
+		env:          globals,
+		locals:       map[Expr]*int{},
+		loopTargets:  map[Stmt]Stmt{},
+		stringifying: map[interface{}]bool{},
+		modules:      map[string]*LoxModule{},
+		random:       rand.New(rand.NewSource(time.Now().UnixNano())),
+		stdin:        bufio.NewReader(os.Stdin),
+		capabilities: map[Capability]bool{},
+
+		declaredClasses: map[string]bool{},
+		declaredTypes:   map[string]bool{},
+	}
 
-	return &Interpreter{
-		env:  globals,
-		prev: nil,
+	for _, option := range options {
+		option(interpreter)
 	}
+
+	globals.define("clock", NewClock())
+	defineReflection(globals)
+	defineCollections(globals)
+	defineFunctional(globals)
+	defineStrings(globals)
+	defineMath(globals)
+	interpreter.defineIO()
+	interpreter.defineOS()
+	defineJSON(globals)
+	defineTime(globals)
+	defineRegex(globals)
+
+	interpreter.definePrelude()
+
+	interpreter.module = NewLoxModule("main", "", NewEnvironment(globals))
+	interpreter.env = interpreter.module.env
+
+	return interpreter
 }
 
 func (interpreter *Interpreter) Interpret(statements []Stmt) {
 	defer func() {
 		if r := recover(); r != nil {
-			if !loxerror.HadRuntimeError() {
-				if err, ok := r.(error); ok {
-					fmt.Println(err.Error())
-				} else {
-					fmt.Println("Runtime error occurred.")
-				}
+			ex := interpreter.exception(r)
+			interpreter.unwind(interpreter.module.env, nil, 0)
+
+			if exit, ok := r.(*Exit); ok {
+				panic(exit)
+			}
+
+			if ex != nil {
+				interpreter.report(ex)
+			} else if err, ok := r.(error); ok {
+				fmt.Println(err.Error())
+			} else {
+				fmt.Println("Runtime error occurred.")
 			}
 		}
 	}()
@@ -45,12 +120,18 @@ func (interpreter *Interpreter) Interpret(statements []Stmt) {
 	}
 }
 
-func (interpreter *Interpreter) execute(stmt Stmt) {
-	stmt.accept(interpreter)
+// execute runs stmt and returns the loopJump it completed with, if any.
+func (interpreter *Interpreter) execute(stmt Stmt) *loopJump {
+	jump, _ := stmt.accept(interpreter).(*loopJump)
+	return jump
 }
 
 func (interpreter *Interpreter) resolve(expr Expr, depth *int) {
-	locals[expr] = depth
+	interpreter.locals[expr] = depth
+}
+
+func (interpreter *Interpreter) resolveLoop(stmt Stmt, loop Stmt) {
+	interpreter.loopTargets[stmt] = loop
 }
 
 func (interpreter *Interpreter) visitReturnCmdStmt(stmt *ReturnCmd) interface{} {
@@ -65,49 +146,149 @@ func (interpreter *Interpreter) visitReturnCmdStmt(stmt *ReturnCmd) interface{}
 
 func (interpreter *Interpreter) visitFunctionStmt(stmt *Function) interface{} {
 	function := NewLoxFunction(stmt, interpreter.env, false, false)
-	globals.define(stmt.name.Lexeme, function)
+	interpreter.env.define(stmt.name.Lexeme, function)
 
 	return nil
 }
 
 func (interpreter *Interpreter) visitContinueCmdStmt(continueCmd *ContinueCmd) interface{} {
-	env := interpreter.env
-	for depth := continueCmd.envDepth; depth > 0; depth-- {
-		env.continuing = true
-		env = env.enclosing
+	return &loopJump{target: interpreter.loopTargets[continueCmd], isContinue: true}
+}
+
+func (interpreter *Interpreter) visitBreakCmdStmt(breakCmd *BreakCmd) interface{} {
+	return &loopJump{target: interpreter.loopTargets[breakCmd], isContinue: false}
+}
+
+func (interpreter *Interpreter) visitWhileLoopStmt(whileLoop *WhileLoop) interface{} {
+	for isTruthy(interpreter.evaluate(whileLoop.condition)) {
+		if jump := interpreter.execute(whileLoop.body); jump != nil {
+			if jump.target != whileLoop {
+				return jump
+			}
+
+			if !jump.isContinue {
+				break
+			}
+		}
+
+		if whileLoop.increment != nil {
+			interpreter.evaluate(whileLoop.increment)
+		}
 	}
 
 	return nil
 }
 
-func (interpreter *Interpreter) visitBreakCmdStmt(breakCmd *BreakCmd) interface{} {
-	env := interpreter.env
-	for depth := breakCmd.envDepth; depth > 0; depth-- {
-		env.exit = true
-		env = env.enclosing
+func (interpreter *Interpreter) visitDoWhileStmt(stmt *DoWhile) interface{} {
+	for {
+		if jump := interpreter.execute(stmt.body); jump != nil {
+			if jump.target != stmt {
+				return jump
+			}
+
+			if !jump.isContinue {
+				break
+			}
+		}
+
+		if isTruthy(interpreter.evaluate(stmt.condition)) == stmt.until {
+			break
+		}
 	}
 
 	return nil
 }
 
-func (interpreter *Interpreter) visitWhileLoopStmt(whileLoop *WhileLoop) interface{} {
-	for isTruthy(interpreter.evaluate(whileLoop.condition)) {
-		interpreter.execute(whileLoop.body)
+func (interpreter *Interpreter) visitForInStmt(stmt *ForIn) interface{} {
+	iterable := interpreter.evaluate(stmt.iterable)
+	previous := interpreter.env
 
-		if interpreter.env.continuing {
-			interpreter.env = interpreter.prev
-			continue
+	var outer *loopJump
+	run := func(values ...interface{}) bool {
+		env := NewEnvironment(previous)
+		for i, variable := range stmt.variables {
+			env.define(variable.Lexeme, values[i])
 		}
 
-		if interpreter.env.exit {
-			interpreter.env = interpreter.prev
-			break
+		jump := interpreter.executeBlock([]Stmt{stmt.body}, env)
+		if jump == nil {
+			return true
+		}
+
+		if jump.target != stmt {
+			outer = jump
+			return false
+		}
+
+		return jump.isContinue
+	}
+
+	if m, ok := iterable.(*LoxMap); ok && len(stmt.variables) == 2 {
+		for _, entry := range append([]*mapEntry(nil), m.entries...) {
+			if !run(entry.key, entry.value) {
+				break
+			}
+		}
+
+		return outer
+	}
+
+	interpreter.iterate(stmt.keyword, iterable, func(item interface{}) bool {
+		if len(stmt.variables) == 1 {
+			return run(item)
+		}
+
+		var pair []interface{}
+		switch val := item.(type) {
+		case *LoxList:
+			pair = val.elements
+		case *LoxTuple:
+			pair = val.elements
+		}
+
+		if len(pair) != 2 {
+			throwRuntimeError(stmt.variables[1], fmt.Sprintf("Can't destructure %s into two variables.", interpreter.repr(item)))
+		}
+
+		return run(pair[0], pair[1])
+	})
+
+	return outer
+}
+
+func (interpreter *Interpreter) visitMatchStmt(stmt *Match) interface{} {
+	subject := interpreter.evaluate(stmt.subject)
+
+	for i, alternatives := range stmt.patterns {
+		for _, pattern := range alternatives {
+			env := NewEnvironment(interpreter.env)
+			if !interpreter.matches(pattern, subject, env) {
+				continue
+			}
+
+			if stmt.guards[i] != nil && !isTruthy(interpreter.evaluateIn(stmt.guards[i], env)) {
+				continue
+			}
+
+			return interpreter.executeBlock([]Stmt{stmt.bodies[i]}, env)
 		}
 	}
 
 	return nil
 }
 
+// evaluateIn evaluates expr in env, which for a match case is the
+// environment holding that case's bindings.
+func (interpreter *Interpreter) evaluateIn(expr Expr, env *Environment) interface{} {
+	previous := interpreter.env
+	defer func() {
+		interpreter.env = previous
+	}()
+
+	interpreter.env = env
+	return interpreter.evaluate(expr)
+}
+
 func (interpreter *Interpreter) visitLogicalExpr(expr *Logical) interface{} {
 	left := interpreter.evaluate(expr.left)
 
@@ -126,9 +307,9 @@ func (interpreter *Interpreter) visitLogicalExpr(expr *Logical) interface{} {
 
 func (interpreter *Interpreter) visitIfCmdStmt(stmt *IfCmd) interface{} {
 	if isTruthy(interpreter.evaluate(stmt.condition)) {
-		interpreter.execute(stmt.thenBranch)
+		return interpreter.execute(stmt.thenBranch)
 	} else if stmt.elseBranch != nil {
-		interpreter.execute(stmt.elseBranch)
+		return interpreter.execute(stmt.elseBranch)
 	}
 
 	return nil
@@ -144,6 +325,38 @@ func (interpreter *Interpreter) visitGetMethodExpr(expr *GetMethod) interface{}
 		return val.getStaticMethod(expr.name)
 	}
 
+	if val, ok := object.(*LoxList); ok {
+		return val.getMethod(expr.name)
+	}
+
+	if val, ok := object.(*LoxMap); ok {
+		return val.getMethod(expr.name)
+	}
+
+	if val, ok := object.(*LoxTuple); ok {
+		return val.getMethod(expr.name)
+	}
+
+	if val, ok := object.(*LoxSet); ok {
+		return val.getMethod(expr.name)
+	}
+
+	if val, ok := object.(*LoxRange); ok {
+		return val.getMethod(expr.name)
+	}
+
+	if val, ok := object.(*LoxModule); ok {
+		return val.get(expr.name)
+	}
+
+	if val, ok := object.(*LoxDateTime); ok {
+		return val.getMethod(expr.name)
+	}
+
+	if val, ok := object.(*LoxRegex); ok {
+		return val.getMethod(expr.name)
+	}
+
 	throwRuntimeError(expr.name, "Only instances have properties.")
 	return nil
 }
@@ -154,6 +367,10 @@ func (interpreter *Interpreter) visitGetFieldExpr(expr *GetField) interface{} {
 		return val.getField(expr.name)
 	}
 
+	if val, ok := object.(*LoxModule); ok {
+		return val.get(expr.name)
+	}
+
 	throwRuntimeError(expr.name, "Only instances have properties.")
 	return nil
 }
@@ -172,35 +389,185 @@ func (interpreter *Interpreter) visitSetExpr(expr *Set) interface{} {
 	return value
 }
 
-func (interpreter *Interpreter) visitBlockStmt(stmt *Block) interface{} {
-	interpreter.executeBlock(stmt.statements, NewEnvironment(interpreter.env), stmt)
+func (interpreter *Interpreter) visitListLiteralExpr(expr *ListLiteral) interface{} {
+	elements := make([]interface{}, len(expr.elements))
+	for i, element := range expr.elements {
+		elements[i] = interpreter.evaluate(element)
+	}
+
+	return NewLoxList(elements)
+}
+
+func (interpreter *Interpreter) visitMapLiteralExpr(expr *MapLiteral) interface{} {
+	m := NewLoxMap()
+	for i, key := range expr.keys {
+		m.set(interpreter, expr.brace, interpreter.evaluate(key), interpreter.evaluate(expr.values[i]))
+	}
+
+	return m
+}
+
+func (interpreter *Interpreter) visitTupleLiteralExpr(expr *TupleLiteral) interface{} {
+	elements := make([]interface{}, len(expr.elements))
+	for i, element := range expr.elements {
+		elements[i] = interpreter.evaluate(element)
+	}
+
+	return NewLoxTuple(elements)
+}
+
+func (interpreter *Interpreter) visitRangeLiteralExpr(expr *RangeLiteral) interface{} {
+	start := interpreter.evaluate(expr.start)
+	end := interpreter.evaluate(expr.end)
+
+	var step interface{} = float64(1)
+	if expr.step != nil {
+		step = interpreter.evaluate(expr.step)
+	}
+
+	checkNumberOperand(expr.operator, start, end, step)
+	if step.(float64) == 0 {
+		throwRuntimeError(expr.operator, "Range step can't be zero.")
+	}
+
+	if isNonFinite(start.(float64)) || isNonFinite(step.(float64)) {
+		throwRuntimeError(expr.operator, "Range start and step must be finite.")
+	}
+
+	if math.IsNaN(end.(float64)) {
+		throwRuntimeError(expr.operator, "Range end can't be NaN.")
+	}
+
+	return NewLoxRange(start.(float64), end.(float64), step.(float64), expr.operator.Type == references.DotDot)
+}
+
+func isNonFinite(f float64) bool {
+	return math.IsNaN(f) || math.IsInf(f, 0)
+}
+
+func (interpreter *Interpreter) visitIndexExpr(expr *Index) interface{} {
+	object := interpreter.evaluate(expr.object)
+	index := interpreter.evaluate(expr.index)
+
+	if r, ok := index.(*LoxRange); ok {
+		return interpreter.selectRange(expr.bracket, object, r)
+	}
+
+	switch val := object.(type) {
+	case *LoxList:
+		return val.get(expr.bracket, index)
+	case *LoxMap:
+		return val.get(interpreter, expr.bracket, index)
+	case *LoxTuple:
+		return val.get(expr.bracket, index)
+	case string:
+		runes := []rune(val)
+		return string(runes[checkIndex(expr.bracket, index, len(runes))])
+	}
+
+	throwRuntimeError(expr.bracket, "Only lists, maps, tuples and strings can be indexed.")
+	return nil
+}
+
+// selectRange picks the elements of a list, tuple or string at the positions
+// produced by a range, so 'xs[1..3]' works like a slice.
+func (interpreter *Interpreter) selectRange(token *scanner.Token, object interface{}, r *LoxRange) interface{} {
+	switch val := object.(type) {
+	case *LoxList:
+		var elements []interface{}
+		for _, i := range r.positions(token, len(val.elements)) {
+			elements = append(elements, val.elements[i])
+		}
+		return NewLoxList(elements)
+	case *LoxTuple:
+		var elements []interface{}
+		for _, i := range r.positions(token, len(val.elements)) {
+			elements = append(elements, val.elements[i])
+		}
+		return NewLoxTuple(elements)
+	case string:
+		runes := []rune(val)
+
+		var selected []rune
+		for _, i := range r.positions(token, len(runes)) {
+			selected = append(selected, runes[i])
+		}
+		return string(selected)
+	}
+
+	throwRuntimeError(token, "Only lists, tuples and strings can be indexed by a range.")
+	return nil
+}
+
+func (interpreter *Interpreter) visitSetIndexExpr(expr *SetIndex) interface{} {
+	object := interpreter.evaluate(expr.object)
+	index := interpreter.evaluate(expr.index)
+	value := interpreter.evaluate(expr.value)
+
+	switch val := object.(type) {
+	case *LoxList:
+		val.set(expr.bracket, index, value)
+		return value
+	case *LoxMap:
+		val.set(interpreter, expr.bracket, index, value)
+		return value
+	}
+
+	throwRuntimeError(expr.bracket, "Only lists and maps support index assignment.")
 	return nil
 }
 
-func (interpreter *Interpreter) executeBlock(statements []Stmt, env *Environment, block *Block) {
+func (interpreter *Interpreter) visitSliceExpr(expr *Slice) interface{} {
+	object := interpreter.evaluate(expr.object)
+
+	var start, end interface{}
+	if expr.start != nil {
+		start = interpreter.evaluate(expr.start)
+	}
+
+	if expr.end != nil {
+		end = interpreter.evaluate(expr.end)
+	}
+
+	switch val := object.(type) {
+	case *LoxList:
+		return val.slice(expr.bracket, start, end)
+	case *LoxTuple:
+		return val.slice(expr.bracket, start, end)
+	case string:
+		runes := []rune(val)
+		from, to := checkSlice(expr.bracket, start, end, len(runes))
+		return string(runes[from:to])
+	}
+
+	throwRuntimeError(expr.bracket, "Only lists, tuples and strings can be sliced.")
+	return nil
+}
+
+func (interpreter *Interpreter) visitBlockStmt(stmt *Block) interface{} {
+	return interpreter.executeBlock(stmt.statements, NewEnvironment(interpreter.env))
+}
+
+func (interpreter *Interpreter) executeBlock(statements []Stmt, env *Environment) *loopJump {
 	previous := interpreter.env
+	defer func() {
+		interpreter.env = previous
+	}()
 
 	interpreter.env = env
 	for _, statement := range statements {
-		interpreter.execute(statement)
-		if interpreter.env.continuing && block != nil && block.isLoopIncrementer {
-			interpreter.env.continuing = false
-			continue
-		}
-
-		if interpreter.env.exit || interpreter.env.continuing {
-			interpreter.prev = previous
-			return
+		if jump := interpreter.execute(statement); jump != nil {
+			return jump
 		}
 	}
 
-	interpreter.env = previous
+	return nil
 }
 
 func (interpreter *Interpreter) visitAssignExpr(expr *Assign) interface{} {
 	value := interpreter.evaluate(expr.value)
 
-	distance, ok := locals[expr]
+	distance, ok := interpreter.locals[expr]
 	if !ok {
 		interpreter.env.assign(expr.name, value)
 		return value
@@ -209,7 +576,7 @@ func (interpreter *Interpreter) visitAssignExpr(expr *Assign) interface{} {
 	if distance != nil {
 		interpreter.env.assignAt(*distance, expr.name, value)
 	} else {
-		globals.assign(expr.name, value)
+		interpreter.globals.assign(expr.name, value)
 	}
 
 	return value
@@ -235,6 +602,26 @@ func (interpreter *Interpreter) visitClassStmt(stmt *Class) interface{} {
 		}
 	}
 
+	var traits []*LoxTrait
+	for _, t := range stmt.traits {
+		trait, ok := interpreter.evaluate(t).(*LoxTrait)
+		if !ok {
+			throwRuntimeError(t.name, fmt.Sprintf("'%s' is not a trait.", t.name.Lexeme))
+		}
+
+		traits = append(traits, trait)
+	}
+
+	var interfaces []*LoxInterface
+	for _, i := range stmt.interfaces {
+		iface, ok := interpreter.evaluate(i).(*LoxInterface)
+		if !ok {
+			throwRuntimeError(i.name, fmt.Sprintf("'%s' is not an interface.", i.name.Lexeme))
+		}
+
+		interfaces = append(interfaces, iface)
+	}
+
 	interpreter.env.define(stmt.name.Lexeme, nil)
 
 	if stmt.superclass != nil {
@@ -247,7 +634,10 @@ func (interpreter *Interpreter) visitClassStmt(stmt *Class) interface{} {
 		methods[method.name.Lexeme] = NewLoxFunction(method, interpreter.env, method.name.Lexeme == "init" && !method.isStatic, method.isStatic)
 	}
 
+	interpreter.composeTraits(stmt, superclass, traits, methods)
+
 	fields := make(map[string]interface{})
+	var fieldNames []string
 	for _, field := range stmt.fields {
 		var value interface{}
 		if field.initializer != nil {
@@ -255,24 +645,111 @@ func (interpreter *Interpreter) visitClassStmt(stmt *Class) interface{} {
 		}
 
 		fields[field.name.Lexeme] = value
+		fieldNames = append(fieldNames, field.name.Lexeme)
 	}
 
-	class := NewLoxClass(stmt.name.Lexeme, superclass, methods, fields)
+	class := NewLoxClass(stmt.name.Lexeme, superclass, traits, interfaces, methods, fields, fieldNames, stmt.isAbstract)
 
 	if stmt.superclass != nil {
 		interpreter.env = interpreter.env.enclosing
 	}
 
+	if !stmt.isAbstract {
+		if missing := class.missingMethods(); len(missing) > 0 {
+			throwRuntimeError(stmt.name, fmt.Sprintf("Class '%s' is missing implementations for: %s.", stmt.name.Lexeme, strings.Join(missing, ", ")))
+		}
+	}
+
 	interpreter.env.assign(stmt.name, class)
 	return nil
 }
 
+// composeTraits copies trait methods into methods. A method defined by the
+// class itself always wins, and a 'use Trait.method;' clause picks one
+// trait's method over the others; two traits otherwise providing the same
+// concrete method is an error.
+func (interpreter *Interpreter) composeTraits(stmt *Class, superclass *LoxClass, traits []*LoxTrait, methods map[string]*LoxFunction) {
+	chosen := make(map[string]*LoxTrait)
+	for _, use := range stmt.uses {
+		for i, trait := range stmt.traits {
+			if trait.name.Lexeme == use.trait.Lexeme {
+				chosen[use.method.Lexeme] = traits[i]
+			}
+		}
+
+		if _, ok := chosen[use.method.Lexeme].methods[use.method.Lexeme]; !ok {
+			throwRuntimeError(use.method, fmt.Sprintf("Trait '%s' has no method '%s'.", use.trait.Lexeme, use.method.Lexeme))
+		}
+	}
+
+	providers := make(map[string]*LoxTrait)
+	var conflicts []string
+	for _, trait := range traits {
+		for name, method := range trait.methods {
+			if _, ok := methods[name]; ok && providers[name] == nil {
+				continue
+			}
+
+			if choice, ok := chosen[name]; ok {
+				if choice == trait {
+					methods[name] = method
+					providers[name] = trait
+				}
+				continue
+			}
+
+			if method.declaration.isAbstract {
+				if _, ok := methods[name]; ok {
+					continue
+				}
+
+				if superclass != nil {
+					if inherited := superclass.findMethod(name); inherited != nil && !inherited.declaration.isAbstract {
+						continue
+					}
+				}
+			} else if provider, ok := providers[name]; ok && !methods[name].declaration.isAbstract {
+				conflicts = append(conflicts, fmt.Sprintf("'%s' (from '%s' and '%s')", name, provider.name(), trait.name()))
+				continue
+			}
+
+			methods[name] = method
+			providers[name] = trait
+		}
+	}
+
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		throwRuntimeError(stmt.name, fmt.Sprintf("Class '%s' has conflicting trait methods %s; override them in the class or pick one with 'use Trait.method;'.", stmt.name.Lexeme, strings.Join(conflicts, ", ")))
+	}
+}
+
+func (interpreter *Interpreter) visitTraitStmt(stmt *Trait) interface{} {
+	methods := make(map[string]*LoxFunction)
+	for _, method := range stmt.methods {
+		methods[method.name.Lexeme] = NewLoxFunction(method, interpreter.env, false, method.isStatic)
+	}
+
+	interpreter.env.define(stmt.name.Lexeme, NewLoxTrait(stmt.name.Lexeme, methods))
+	return nil
+}
+
+func (interpreter *Interpreter) visitInterfaceCmdStmt(stmt *InterfaceCmd) interface{} {
+	methods := make(map[string]*Function)
+	for _, method := range stmt.methods {
+		methods[method.name.Lexeme] = method
+	}
+
+	interpreter.env.define(stmt.name.Lexeme, NewLoxInterface(stmt.name.Lexeme, methods))
+	return nil
+}
+
 func (interpreter *Interpreter) visitVariableExpr(expr *Variable) interface{} {
 	return interpreter.lookupVariable(expr.name, expr)
 }
 
 func (interpreter *Interpreter) lookupVariable(name *scanner.Token, expr Expr) interface{} {
-	distance, ok := locals[expr]
+	distance, ok := interpreter.locals[expr]
 	if !ok {
 		return interpreter.env.get(name)
 	}
@@ -281,7 +758,7 @@ func (interpreter *Interpreter) lookupVariable(name *scanner.Token, expr Expr) i
 		return interpreter.env.getAt(*distance, name.Lexeme)
 	}
 
-	return globals.get(name)
+	return interpreter.globals.get(name)
 }
 
 func (interpreter *Interpreter) visitExpressionStmt(stmt *Expression) interface{} {
@@ -291,7 +768,7 @@ func (interpreter *Interpreter) visitExpressionStmt(stmt *Expression) interface{
 
 func (interpreter *Interpreter) visitPrintStmt(stmt *Print) interface{} {
 	value := interpreter.evaluate(stmt.expression)
-	fmt.Println(stringify(value))
+	fmt.Println(interpreter.stringify(value))
 	return nil
 }
 
@@ -334,10 +811,22 @@ func (interpreter *Interpreter) visitBinaryExpr(expr *Binary) interface{} {
 	case references.LessEqual:
 		checkNumberOperand(expr.operator, left, right)
 		return left.(float64) <= right.(float64)
+	case references.Is:
+		_, isClass := right.(*LoxClass)
+		_, isTrait := right.(*LoxTrait)
+		_, isInterface := right.(*LoxInterface)
+		if !isClass && !isTrait && !isInterface {
+			throwRuntimeError(expr.operator, "Right operand of 'is' must be a class, trait or interface.")
+		}
+
+		if instance, ok := left.(*LoxInstance); ok {
+			return instance.class.isSubtypeOf(right)
+		}
+		return false
 	case references.BangEqual:
-		return !isEqual(left, right)
+		return !interpreter.isEqual(left, right)
 	case references.EqualEqual:
-		return isEqual(left, right)
+		return interpreter.isEqual(left, right)
 	case references.Minus:
 		checkNumberOperand(expr.operator, left, right)
 		return left.(float64) - right.(float64)
@@ -363,7 +852,7 @@ func (interpreter *Interpreter) visitBinaryExpr(expr *Binary) interface{} {
 		_, lOk = left.(string)
 		_, rOk = right.(string)
 		if lOk || rOk {
-			return fmt.Sprintf("%v%v", left, right)
+			return interpreter.stringify(left) + interpreter.stringify(right)
 		}
 
 		throwRuntimeError(expr.operator, "Operands must be two numbers or two strings.")
@@ -377,7 +866,7 @@ func (interpreter *Interpreter) evaluate(expr Expr) interface{} {
 }
 
 func (interpreter *Interpreter) visitSuperExpr(expr *Super) interface{} {
-	distance := locals[expr]
+	distance := interpreter.locals[expr]
 	superclass := interpreter.env.getAt(*distance, "super").(*LoxClass)
 	object := interpreter.env.getAt(*distance-1, "this").(*LoxInstance)
 
@@ -386,9 +875,81 @@ func (interpreter *Interpreter) visitSuperExpr(expr *Super) interface{} {
 		throwRuntimeError(expr.method, fmt.Sprintf("Undefined property '%s'.", expr.method.Lexeme))
 	}
 
+	if method.declaration.isAbstract {
+		throwRuntimeError(expr.method, fmt.Sprintf("Can't call abstract method '%s'.", expr.method.Lexeme))
+	}
+
 	return method.bind(object)
 }
 
+// visitDeferCmdStmt schedules expr to run when the enclosing function exits.
+// As in Go, the callee and arguments of a deferred call are evaluated now.
+func (interpreter *Interpreter) visitDeferCmdStmt(stmt *DeferCmd) interface{} {
+	env := interpreter.env
+
+	run := func() {
+		interpreter.evaluateIn(stmt.expression, env)
+	}
+
+	if call, ok := stmt.expression.(*Call); ok {
+		callee := interpreter.evaluate(call.callee)
+		if v, ok := callee.(*LoxFunction); ok && v == nil {
+			throwRuntimeError(call.paren, "Could not find function or method.")
+		}
+
+		var arguments []interface{}
+		for _, arg := range call.arguments {
+			arguments = append(arguments, interpreter.evaluate(arg))
+		}
+
+		run = func() {
+			interpreter.invoke(call, callee, arguments)
+		}
+	}
+
+	top := len(interpreter.deferred) - 1
+	interpreter.deferred[top] = append(interpreter.deferred[top], run)
+	return nil
+}
+
+// runDeferred runs the calls deferred by the function that is exiting, most
+// recent first. It is itself deferred by LoxFunction.call, so it also runs
+// while an exception unwinds; an exception thrown by a deferred call
+// replaces the one in flight.
+func (interpreter *Interpreter) runDeferred(depth int) {
+	r := recover()
+	if r != nil {
+		if ex := interpreter.exception(r); ex != nil {
+			r = ex
+		}
+		interpreter.frames = interpreter.frames[:depth]
+	}
+
+	top := len(interpreter.deferred) - 1
+	calls := interpreter.deferred[top]
+	interpreter.deferred = interpreter.deferred[:top]
+
+	for i := len(calls) - 1; i >= 0; i-- {
+		func() {
+			defer func() {
+				if p := recover(); p != nil {
+					if ex := interpreter.exception(p); ex != nil {
+						p = ex
+					}
+					interpreter.frames = interpreter.frames[:depth]
+					r = p
+				}
+			}()
+
+			calls[i]()
+		}()
+	}
+
+	if r != nil {
+		panic(r)
+	}
+}
+
 func (interpreter *Interpreter) visitThisExpr(expr *This) interface{} {
 	return interpreter.lookupVariable(expr.keyword, expr)
 }
@@ -404,16 +965,41 @@ func (interpreter *Interpreter) visitCallExpr(expr *Call) interface{} {
 		arguments = append(arguments, interpreter.evaluate(arg))
 	}
 
+	return interpreter.invoke(expr, callee, arguments)
+}
+
+// invoke calls an already evaluated callee with its evaluated arguments.
+func (interpreter *Interpreter) invoke(expr *Call, callee interface{}, arguments []interface{}) interface{} {
 	if _, ok := callee.(LoxCallable); !ok {
 		throwRuntimeError(expr.paren, fmt.Sprintf("Can only call functions and classes but tried to call '%v'.", callee))
 	}
 
+	class, isClass := callee.(*LoxClass)
+	if isClass && !expr.isNew {
+		throwRuntimeError(expr.paren, fmt.Sprintf("Expected 'new' before instantiation of class '%s'.", class.name()))
+	}
+
+	if !isClass && expr.isNew {
+		throwRuntimeError(expr.paren, fmt.Sprintf("Can only use 'new' with a class but tried to instantiate '%v'.", callee))
+	}
+
+	if isClass && class.isAbstract {
+		throwRuntimeError(expr.paren, fmt.Sprintf("Can't instantiate abstract class '%s'.", class.name()))
+	}
+
 	function := callee.(LoxCallable)
-	if len(arguments) != function.arity() {
+	if function.arity() >= 0 && len(arguments) != function.arity() {
 		throwRuntimeError(expr.paren, fmt.Sprintf("Expected %d arguments but got %d for %s '%s'.", function.arity(), len(arguments), strings.ToLower(references.GetFunctionTypeName(function.callableType())), function.name()))
 	}
 
-	return function.call(interpreter, arguments)
+	previous := interpreter.callToken
+	interpreter.callToken = expr.paren
+	interpreter.frames = append(interpreter.frames, callFrame{name: function.name(), line: expr.paren.Line})
+	result := function.call(interpreter, arguments)
+	interpreter.frames = interpreter.frames[:len(interpreter.frames)-1]
+	interpreter.callToken = previous
+
+	return result
 }
 
 func checkNumberOperand(operator *scanner.Token, operands ...interface{}) {
@@ -447,7 +1033,7 @@ func isTruthy(obj interface{}) bool {
 	return true
 }
 
-func isEqual(a interface{}, b interface{}) bool {
+func (interpreter *Interpreter) isEqual(a interface{}, b interface{}) bool {
 	if a == nil && b == nil {
 		return true
 	}
@@ -456,16 +1042,149 @@ func isEqual(a interface{}, b interface{}) bool {
 		return false
 	}
 
+	if method := equalityMethod(a, "equals", 1); method != nil {
+		return isTruthy(method.call(interpreter, []interface{}{b}))
+	}
+
+	if method := equalityMethod(b, "equals", 1); method != nil {
+		return isTruthy(method.call(interpreter, []interface{}{a}))
+	}
+
+	switch left := a.(type) {
+	case *LoxTuple:
+		right, ok := b.(*LoxTuple)
+		if !ok || len(left.elements) != len(right.elements) {
+			return false
+		}
+
+		for i := range left.elements {
+			if !interpreter.isEqual(left.elements[i], right.elements[i]) {
+				return false
+			}
+		}
+
+		return true
+	case *LoxSet:
+		right, ok := b.(*LoxSet)
+		if !ok || len(left.items.entries) != len(right.items.entries) {
+			return false
+		}
+
+		for _, element := range left.elements() {
+			if !right.has(interpreter, interpreter.callToken, element) {
+				return false
+			}
+		}
+
+		return true
+	case *LoxDateTime:
+		right, ok := b.(*LoxDateTime)
+		return ok && left.time.Equal(right.time)
+	}
+
 	return a == b
 }
 
-func stringify(obj interface{}) string {
+// hash returns a hash for value that is consistent with isEqual, so that
+// values which compare equal always land in the same bucket. Instances may
+// provide their own hash() method; classes that only define equals() fall
+// back to hashing by class, which is slow but still correct.
+func (interpreter *Interpreter) hash(value interface{}, token *scanner.Token) uint64 {
+	switch val := value.(type) {
+	case nil:
+		return 0
+	case bool:
+		if val {
+			return 1
+		}
+		return 2
+	case float64:
+		if val == 0 {
+			val = 0
+		}
+		return hashBytes([]byte(strconv.FormatUint(math.Float64bits(val), 16)))
+	case string:
+		return hashBytes([]byte(val))
+	case *LoxInstance:
+		if method := equalityMethod(val, "hash", 0); method != nil {
+			result, ok := method.call(interpreter, nil).(float64)
+			if !ok {
+				throwRuntimeError(token, fmt.Sprintf("'%s.hash()' must return a number.", val.class.name()))
+			}
+			return interpreter.hash(result, token)
+		}
+
+		if equalityMethod(val, "equals", 1) != nil {
+			return uint64(reflect.ValueOf(val.class).Pointer())
+		}
+	case *LoxTuple:
+		var h uint64 = 17
+		for _, element := range val.elements {
+			h = h*31 + interpreter.hash(element, token)
+		}
+		return h
+	case *LoxSet:
+		var h uint64 = 19
+		for _, element := range val.elements() {
+			h += interpreter.hash(element, token)
+		}
+		return h
+	}
+
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr {
+		return uint64(v.Pointer())
+	}
+
+	return hashBytes([]byte(fmt.Sprintf("%v", value)))
+}
+
+func hashBytes(data []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(data)
+	return h.Sum64()
+}
+
+func equalityMethod(value interface{}, name string, arity int) *LoxFunction {
+	instance, ok := value.(*LoxInstance)
+	if !ok {
+		return nil
+	}
+
+	method := instance.class.findMethod(name)
+	if method == nil || method.isStatic || method.arity() != arity {
+		return nil
+	}
+
+	return method.bind(instance)
+}
+
+func (interpreter *Interpreter) stringify(obj interface{}) string {
 	if obj == nil {
 		return "nil"
 	}
 
 	if f, ok := obj.(float64); ok {
-		return strconv.FormatFloat(f, 'f', -1, 64)
+		return formatNumber(f)
+	}
+
+	if val, ok := obj.(*LoxInstance); ok {
+		return interpreter.stringifyInstance(val)
+	}
+
+	if val, ok := obj.(*LoxList); ok {
+		return interpreter.stringifyList(val)
+	}
+
+	if val, ok := obj.(*LoxMap); ok {
+		return interpreter.stringifyMap(val)
+	}
+
+	if val, ok := obj.(*LoxTuple); ok {
+		return interpreter.stringifyTuple(val)
+	}
+
+	if val, ok := obj.(*LoxSet); ok {
+		return interpreter.stringifySet(val)
 	}
 
 	if val, ok := obj.(LoxCallable); ok {
@@ -474,3 +1193,103 @@ func stringify(obj interface{}) string {
 
 	return fmt.Sprintf("%v", obj)
 }
+
+func (interpreter *Interpreter) stringifyInstance(instance *LoxInstance) string {
+	if interpreter.stringifying[instance] {
+		return "..."
+	}
+
+	interpreter.stringifying[instance] = true
+	defer delete(interpreter.stringifying, instance)
+
+	if method := instance.class.findMethod("toString"); method != nil && !method.isStatic && method.arity() == 0 {
+		return interpreter.stringify(method.bind(instance).call(interpreter, nil))
+	}
+
+	names := make([]string, 0, len(instance.fields))
+	for name := range instance.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]string, len(names))
+	for i, name := range names {
+		fields[i] = fmt.Sprintf("%s: %s", name, interpreter.repr(instance.fields[name]))
+	}
+
+	return fmt.Sprintf("%s {%s}", instance.name(), strings.Join(fields, ", "))
+}
+
+func (interpreter *Interpreter) stringifyList(list *LoxList) string {
+	if interpreter.stringifying[list] {
+		return "[...]"
+	}
+
+	interpreter.stringifying[list] = true
+	defer delete(interpreter.stringifying, list)
+
+	elements := make([]string, len(list.elements))
+	for i, element := range list.elements {
+		elements[i] = interpreter.repr(element)
+	}
+
+	return fmt.Sprintf("[%s]", strings.Join(elements, ", "))
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func (interpreter *Interpreter) stringifyMap(m *LoxMap) string {
+	if interpreter.stringifying[m] {
+		return "{...}"
+	}
+
+	interpreter.stringifying[m] = true
+	defer delete(interpreter.stringifying, m)
+
+	entries := make([]string, len(m.entries))
+	for i, entry := range m.entries {
+		entries[i] = fmt.Sprintf("%s: %s", interpreter.repr(entry.key), interpreter.repr(entry.value))
+	}
+
+	return fmt.Sprintf("{%s}", strings.Join(entries, ", "))
+}
+
+func (interpreter *Interpreter) stringifyTuple(tuple *LoxTuple) string {
+	elements := make([]string, len(tuple.elements))
+	for i, element := range tuple.elements {
+		elements[i] = interpreter.repr(element)
+	}
+
+	if len(elements) == 1 {
+		return fmt.Sprintf("(%s,)", elements[0])
+	}
+
+	return fmt.Sprintf("(%s)", strings.Join(elements, ", "))
+}
+
+func (interpreter *Interpreter) stringifySet(set *LoxSet) string {
+	elements := set.elements()
+
+	items := make([]string, len(elements))
+	for i, element := range elements {
+		items[i] = interpreter.repr(element)
+	}
+
+	if set.frozen {
+		return fmt.Sprintf("frozenSet(%s)", strings.Join(items, ", "))
+	}
+
+	return fmt.Sprintf("set(%s)", strings.Join(items, ", "))
+}
+
+// repr stringifies values nested inside other values, quoting strings so
+// that "1" and 1 can be told apart.
+func (interpreter *Interpreter) repr(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+
+	return interpreter.stringify(value)
+}
@@ -0,0 +1,158 @@
+package syntax
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// defineIO defines the io module for files, stdin and stderr. Failures are
+// raised as runtime errors, so scripts can handle them with try/catch.
+func (interpreter *Interpreter) defineIO() {
+	interpreter.globals.define("io", interpreter.newHostModule("io",
+		requires(FS_READ, NewNativeFunction("readFile", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			path := interpreter.stringArgument("readFile", arguments, 0)
+
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				interpreter.nativeError("Could not read file '%s': %s", path, err.Error())
+			}
+
+			return string(data)
+		})),
+
+		requires(FS_READ, NewNativeFunction("readLines", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			path := interpreter.stringArgument("readLines", arguments, 0)
+
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				interpreter.nativeError("Could not read file '%s': %s", path, err.Error())
+			}
+
+			var lines []interface{}
+			for _, line := range strings.SplitAfter(string(data), "\n") {
+				if line != "" {
+					lines = append(lines, trimNewline(line))
+				}
+			}
+
+			return NewLoxList(lines)
+		})),
+
+		requires(FS_WRITE, NewNativeFunction("writeFile", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			path := interpreter.stringArgument("writeFile", arguments, 0)
+
+			if err := ioutil.WriteFile(path, []byte(interpreter.stringArgument("writeFile", arguments, 1)), 0644); err != nil {
+				interpreter.nativeError("Could not write file '%s': %s", path, err.Error())
+			}
+
+			return nil
+		})),
+
+		requires(FS_WRITE, NewNativeFunction("appendFile", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			path := interpreter.stringArgument("appendFile", arguments, 0)
+			content := interpreter.stringArgument("appendFile", arguments, 1)
+
+			file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+			if err == nil {
+				_, err = file.WriteString(content)
+				if closeErr := file.Close(); err == nil {
+					err = closeErr
+				}
+			}
+
+			if err != nil {
+				interpreter.nativeError("Could not append to file '%s': %s", path, err.Error())
+			}
+
+			return nil
+		})),
+
+		requires(FS_READ, NewNativeFunction("exists", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			_, err := os.Stat(interpreter.stringArgument("exists", arguments, 0))
+			return err == nil
+		})),
+
+		requires(FS_READ, NewNativeFunction("listDir", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			path := interpreter.stringArgument("listDir", arguments, 0)
+
+			infos, err := ioutil.ReadDir(path)
+			if err != nil {
+				interpreter.nativeError("Could not list directory '%s': %s", path, err.Error())
+			}
+
+			names := make([]string, len(infos))
+			for i, info := range infos {
+				names[i] = info.Name()
+			}
+
+			return sortedNames(names)
+		})),
+
+		requires(FS_WRITE, NewNativeFunction("mkdir", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			path := interpreter.stringArgument("mkdir", arguments, 0)
+
+			if err := os.MkdirAll(path, 0755); err != nil {
+				interpreter.nativeError("Could not create directory '%s': %s", path, err.Error())
+			}
+
+			return nil
+		})),
+
+		requires(FS_WRITE, NewNativeFunction("remove", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			path := interpreter.stringArgument("remove", arguments, 0)
+
+			if err := os.Remove(path); err != nil {
+				interpreter.nativeError("Could not remove '%s': %s", path, err.Error())
+			}
+
+			return nil
+		})),
+
+		requires(STDIN, NewNativeFunction("input", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			interpreter.checkArity("input", arguments, 0, 1)
+			if len(arguments) == 1 {
+				fmt.Print(interpreter.stringify(arguments[0]))
+			}
+
+			line, ok := interpreter.readLine()
+			if !ok {
+				return nil
+			}
+
+			return line
+		})),
+
+		requires(STDIN, NewNativeFunction("lines", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return NewLoxIterator(func() (interface{}, bool) {
+				return interpreter.readLine()
+			})
+		})),
+
+		always(NewNativeFunction("eprint", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			fmt.Fprintln(os.Stderr, interpreter.stringify(arguments[0]))
+			return nil
+		})),
+	))
+}
+
+// readLine reads the next line of stdin without its line ending. It reports
+// false once stdin is exhausted.
+func (interpreter *Interpreter) readLine() (string, bool) {
+	line, err := interpreter.stdin.ReadString('\n')
+	if err != nil && err != io.EOF {
+		interpreter.nativeError("Could not read stdin: %s", err.Error())
+	}
+
+	if line == "" && err == io.EOF {
+		return "", false
+	}
+
+	return trimNewline(line), true
+}
+
+func trimNewline(line string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+}
@@ -0,0 +1,108 @@
+package syntax
+
+import (
+	"fmt"
+	"golox/scanner"
+)
+
+// iterate calls yield with each item produced by value until yield returns
+// false. Instances take part through an iterator() method returning an object
+// whose next() method returns nil once it is exhausted, or by defining next()
+// themselves.
+func (interpreter *Interpreter) iterate(token *scanner.Token, value interface{}, yield func(item interface{}) bool) {
+	switch val := value.(type) {
+	case *LoxList:
+		for i := 0; i < len(val.elements); i++ {
+			if !yield(val.elements[i]) {
+				return
+			}
+		}
+		return
+	case *LoxTuple:
+		for _, element := range val.elements {
+			if !yield(element) {
+				return
+			}
+		}
+		return
+	case *LoxSet:
+		for _, element := range val.elements() {
+			if !yield(element) {
+				return
+			}
+		}
+		return
+	case *LoxMap:
+		for _, entry := range append([]*mapEntry(nil), val.entries...) {
+			if !yield(entry.key) {
+				return
+			}
+		}
+		return
+	case *LoxRange:
+		for i, n := 0, val.len(); float64(i) < n; i++ {
+			if !yield(val.at(i)) {
+				return
+			}
+		}
+		return
+	case string:
+		for _, r := range val {
+			if !yield(string(r)) {
+				return
+			}
+		}
+		return
+	case *LoxIterator:
+		for {
+			item, ok := val.next()
+			if !ok || !yield(item) {
+				return
+			}
+		}
+	case *LoxInstance:
+		next := interpreter.iteratorOf(token, val)
+		for {
+			item := next.call(interpreter, nil)
+			if item == nil || !yield(item) {
+				return
+			}
+		}
+	}
+
+	throwRuntimeError(token, fmt.Sprintf("Can't iterate over %s.", typeOf(value)))
+}
+
+// LoxIterator is a lazy sequence produced by a native, such as the lines
+// of stdin. It can only be consumed once.
+type LoxIterator struct {
+	next func() (interface{}, bool)
+}
+
+func NewLoxIterator(next func() (interface{}, bool)) *LoxIterator {
+	return &LoxIterator{
+		next: next,
+	}
+}
+
+func (iterator *LoxIterator) String() string {
+	return "<iterator>"
+}
+
+func (interpreter *Interpreter) iteratorOf(token *scanner.Token, instance *LoxInstance) *LoxFunction {
+	if method := equalityMethod(instance, "iterator", 0); method != nil {
+		iterator, ok := method.call(interpreter, nil).(*LoxInstance)
+		if !ok {
+			throwRuntimeError(token, fmt.Sprintf("'%s.iterator()' must return an instance.", instance.class.name()))
+		}
+
+		instance = iterator
+	}
+
+	next := equalityMethod(instance, "next", 0)
+	if next == nil {
+		throwRuntimeError(token, fmt.Sprintf("'%s' instance is not an iterator; define next().", instance.class.name()))
+	}
+
+	return next
+}
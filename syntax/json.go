@@ -0,0 +1,247 @@
+package syntax
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// defineJSON defines the json module. Objects decode to maps that keep the
+// key order of the text; instances encode as objects of their fields.
+func defineJSON(env *Environment) {
+	env.define("json", NewNativeModule("json",
+		NewNativeFunction("parse", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			decoder := json.NewDecoder(strings.NewReader(interpreter.stringArgument("parse", arguments, 0)))
+			decoder.UseNumber()
+
+			value := interpreter.decodeJSON(decoder)
+			if _, err := decoder.Token(); err != io.EOF {
+				interpreter.nativeError("Invalid JSON: unexpected data after the top-level value.")
+			}
+
+			return value
+		}),
+
+		NewNativeFunction("stringify", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			interpreter.checkArity("stringify", arguments, 1, 2)
+
+			encoder := &jsonEncoder{
+				interpreter: interpreter,
+				visiting:    make(map[interface{}]bool),
+			}
+
+			if len(arguments) == 2 {
+				switch indent := arguments[1].(type) {
+				case float64:
+					width := checkInteger(interpreter.callToken, indent, "Indent")
+					if width < 0 {
+						interpreter.nativeError("Indent can't be negative.")
+					}
+
+					encoder.indent = strings.Repeat(" ", width)
+				case string:
+					encoder.indent = indent
+				default:
+					interpreter.nativeError("Argument 2 of 'stringify' must be a number or a string.")
+				}
+			}
+
+			encoder.encode(arguments[0], "")
+			return encoder.out.String()
+		}),
+	))
+}
+
+func (interpreter *Interpreter) decodeJSON(decoder *json.Decoder) interface{} {
+	token, err := decoder.Token()
+	if err != nil {
+		if err == io.EOF {
+			interpreter.nativeError("Invalid JSON: unexpected end of input.")
+		}
+		interpreter.nativeError("Invalid JSON: %s", err.Error())
+	}
+
+	switch val := token.(type) {
+	case json.Delim:
+		if val == '[' {
+			var elements []interface{}
+			for decoder.More() {
+				elements = append(elements, interpreter.decodeJSON(decoder))
+			}
+			decoder.Token()
+
+			return NewLoxList(elements)
+		}
+
+		m := NewLoxMap()
+		for decoder.More() {
+			key := interpreter.decodeJSON(decoder)
+			m.set(interpreter, interpreter.callToken, key, interpreter.decodeJSON(decoder))
+		}
+		decoder.Token()
+
+		return m
+	case json.Number:
+		n, err := val.Float64()
+		if err != nil {
+			interpreter.nativeError("Invalid JSON: number %s is out of range.", val)
+		}
+
+		return n
+	}
+
+	// Strings, booleans and null decode to the matching Go values already.
+	return token
+}
+
+type jsonEncoder struct {
+	interpreter *Interpreter
+	indent      string
+	out         strings.Builder
+	visiting    map[interface{}]bool
+}
+
+func (encoder *jsonEncoder) encode(value interface{}, prefix string) {
+	switch val := value.(type) {
+	case nil:
+		encoder.out.WriteString("null")
+	case bool:
+		encoder.out.WriteString(fmt.Sprintf("%t", val))
+	case float64:
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			encoder.interpreter.nativeError("Can't convert %s to JSON.", formatNumber(val))
+		}
+		encoder.out.WriteString(formatNumber(val))
+	case string:
+		encoder.out.WriteString(quoteJSON(val))
+	case *LoxList:
+		encoder.enter(val)
+		encoder.array(val.elements, prefix)
+		delete(encoder.visiting, val)
+	case *LoxTuple:
+		encoder.array(val.elements, prefix)
+	case *LoxSet:
+		encoder.array(val.elements(), prefix)
+	case *LoxMap:
+		encoder.enter(val)
+		keys := make([]string, len(val.entries))
+		values := make([]interface{}, len(val.entries))
+		for i, entry := range val.entries {
+			key, ok := entry.key.(string)
+			if !ok {
+				encoder.interpreter.nativeError("Can't convert map with %s key %s to JSON; keys must be strings.", typeOf(entry.key), encoder.interpreter.repr(entry.key))
+			}
+
+			keys[i], values[i] = key, entry.value
+		}
+		encoder.object(keys, values, prefix)
+		delete(encoder.visiting, val)
+	case *LoxInstance:
+		encoder.enter(val)
+		keys := instanceFieldOrder(val)
+		values := make([]interface{}, len(keys))
+		for i, key := range keys {
+			values[i] = val.fields[key]
+		}
+		encoder.object(keys, values, prefix)
+		delete(encoder.visiting, val)
+	default:
+		encoder.interpreter.nativeError("Can't convert %s to JSON.", typeOf(value))
+	}
+}
+
+func (encoder *jsonEncoder) enter(value interface{}) {
+	if encoder.visiting[value] {
+		encoder.interpreter.nativeError("Can't convert a cyclic structure to JSON.")
+	}
+
+	encoder.visiting[value] = true
+}
+
+func (encoder *jsonEncoder) array(elements []interface{}, prefix string) {
+	if len(elements) == 0 {
+		encoder.out.WriteString("[]")
+		return
+	}
+
+	inner := prefix + encoder.indent
+	encoder.out.WriteString("[")
+	for i, element := range elements {
+		encoder.separate(i, inner)
+		encoder.encode(element, inner)
+	}
+	encoder.close(prefix, "]")
+}
+
+func (encoder *jsonEncoder) object(keys []string, values []interface{}, prefix string) {
+	if len(keys) == 0 {
+		encoder.out.WriteString("{}")
+		return
+	}
+
+	colon := ":"
+	if encoder.indent != "" {
+		colon = ": "
+	}
+
+	inner := prefix + encoder.indent
+	encoder.out.WriteString("{")
+	for i, key := range keys {
+		encoder.separate(i, inner)
+		encoder.out.WriteString(quoteJSON(key) + colon)
+		encoder.encode(values[i], inner)
+	}
+	encoder.close(prefix, "}")
+}
+
+func (encoder *jsonEncoder) separate(i int, inner string) {
+	if i > 0 {
+		encoder.out.WriteString(",")
+	}
+
+	if encoder.indent != "" {
+		encoder.out.WriteString("\n" + inner)
+	}
+}
+
+func (encoder *jsonEncoder) close(prefix string, delim string) {
+	if encoder.indent != "" {
+		encoder.out.WriteString("\n" + prefix)
+	}
+
+	encoder.out.WriteString(delim)
+}
+
+// instanceFieldOrder lists an instance's fields in declaration order,
+// followed by any fields added at runtime in name order.
+func instanceFieldOrder(instance *LoxInstance) []string {
+	var names []string
+	for _, name := range instance.class.declaredFields() {
+		if _, ok := instance.fields[name]; ok {
+			names = append(names, name)
+		}
+	}
+
+	var extra []string
+	for name := range instance.fields {
+		if !containsName(names, name) {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+
+	return append(names, extra...)
+}
+
+func quoteJSON(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
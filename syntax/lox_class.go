@@ -1,23 +1,34 @@
 package syntax
 
 import (
+	"fmt"
 	"golox/references"
 	"golox/scanner"
+	"sort"
+	"strings"
 )
 
 type LoxClass struct {
 	className  string
 	superclass *LoxClass
+	traits     []*LoxTrait
+	interfaces []*LoxInterface
 	methods    map[string]*LoxFunction
 	fields     map[string]interface{}
+	fieldNames []string
+	isAbstract bool
 }
 
-func NewLoxClass(name string, superclass *LoxClass, methods map[string]*LoxFunction, fields map[string]interface{}) *LoxClass {
+func NewLoxClass(name string, superclass *LoxClass, traits []*LoxTrait, interfaces []*LoxInterface, methods map[string]*LoxFunction, fields map[string]interface{}, fieldNames []string, isAbstract bool) *LoxClass {
 	return &LoxClass{
 		className:  name,
 		superclass: superclass,
+		traits:     traits,
+		interfaces: interfaces,
 		methods:    methods,
 		fields:     fields,
+		fieldNames: fieldNames,
+		isAbstract: isAbstract,
 	}
 }
 
@@ -38,6 +49,108 @@ func (class *LoxClass) findMethod(name string) *LoxFunction {
 	return nil
 }
 
+// missingMethods lists the signatures of every abstract or interface method
+// in the class hierarchy that has no concrete implementation.
+func (class *LoxClass) missingMethods() []string {
+	required := make(map[string]*Function)
+	for c := class; c != nil; c = c.superclass {
+		for name, method := range c.methods {
+			if _, ok := required[name]; !ok && method.declaration.isAbstract {
+				required[name] = method.declaration
+			}
+		}
+
+		for _, iface := range c.interfaces {
+			for name, method := range iface.methods {
+				if _, ok := required[name]; !ok {
+					required[name] = method
+				}
+			}
+		}
+	}
+
+	var missing []string
+	for name, signature := range required {
+		method := class.findMethod(name)
+		if method == nil || method.isStatic || method.declaration.isAbstract || method.arity() != len(signature.params) {
+			params := make([]string, len(signature.params))
+			for i, param := range signature.params {
+				params[i] = param.Lexeme
+			}
+
+			missing = append(missing, fmt.Sprintf("%s(%s)", name, strings.Join(params, ", ")))
+		}
+	}
+
+	sort.Strings(missing)
+	return missing
+}
+
+// positionalFields lists the fields class patterns bind, in order: the
+// fields declared in the class body, superclass fields first, or the
+// parameters of init when the body declares none.
+func (class *LoxClass) positionalFields() []string {
+	if names := class.declaredFields(); len(names) > 0 {
+		return names
+	}
+
+	var names []string
+	if init := class.findMethod("init"); init != nil {
+		for _, param := range init.declaration.params {
+			names = append(names, param.Lexeme)
+		}
+	}
+
+	return names
+}
+
+func (class *LoxClass) declaredFields() []string {
+	var names []string
+	if class.superclass != nil {
+		names = class.superclass.declaredFields()
+	}
+
+	for _, name := range class.fieldNames {
+		if !containsName(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (class *LoxClass) isSubtypeOf(target interface{}) bool {
+	for c := class; c != nil; c = c.superclass {
+		if c == target {
+			return true
+		}
+
+		for _, trait := range c.traits {
+			if trait == target {
+				return true
+			}
+		}
+
+		for _, iface := range c.interfaces {
+			if iface == target {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func (class *LoxClass) call(interpreter *Interpreter, arguments []interface{}) interface{} {
 	instance := NewLoxInstance(class)
 
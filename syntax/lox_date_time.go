@@ -0,0 +1,124 @@
+package syntax
+
+import (
+	"fmt"
+	"golox/scanner"
+	"time"
+)
+
+// LoxDateTime is an instant in a particular time zone. It is immutable; the
+// arithmetic methods return new values.
+type LoxDateTime struct {
+	time time.Time
+}
+
+func NewLoxDateTime(t time.Time) *LoxDateTime {
+	return &LoxDateTime{
+		time: t,
+	}
+}
+
+func (dt *LoxDateTime) String() string {
+	return dt.time.Format(time.RFC3339Nano)
+}
+
+func (dt *LoxDateTime) getMethod(name *scanner.Token) interface{} {
+	switch name.Lexeme {
+	case "year":
+		return dt.field("year", float64(dt.time.Year()))
+	case "month":
+		return dt.field("month", float64(dt.time.Month()))
+	case "day":
+		return dt.field("day", float64(dt.time.Day()))
+	case "hour":
+		return dt.field("hour", float64(dt.time.Hour()))
+	case "minute":
+		return dt.field("minute", float64(dt.time.Minute()))
+	case "second":
+		return dt.field("second", float64(dt.time.Second()))
+	case "millisecond":
+		return dt.field("millisecond", float64(dt.time.Nanosecond()/int(time.Millisecond)))
+	case "weekday":
+		return dt.field("weekday", float64(dt.time.Weekday()))
+	case "dayOfYear":
+		return dt.field("dayOfYear", float64(dt.time.YearDay()))
+	case "zone":
+		return dt.field("zone", dt.time.Location().String())
+	case "unixMillis":
+		return dt.field("unixMillis", float64(dt.time.UnixNano())/float64(time.Millisecond))
+	case "format":
+		return NewNativeFunction("format", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			interpreter.checkArity("format", arguments, 0, 1)
+			if len(arguments) == 0 {
+				return dt.String()
+			}
+
+			return dt.time.Format(interpreter.stringArgument("format", arguments, 0))
+		})
+	case "inZone":
+		return NewNativeFunction("inZone", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return NewLoxDateTime(dt.time.In(interpreter.location(interpreter.stringArgument("inZone", arguments, 0))))
+		})
+	case "utc":
+		return NewNativeFunction("utc", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return NewLoxDateTime(dt.time.UTC())
+		})
+	case "add":
+		return NewNativeFunction("add", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			ms := interpreter.numberArgument("add", arguments, 0)
+			return NewLoxDateTime(dt.time.Add(time.Duration(ms * float64(time.Millisecond))))
+		})
+	case "addDays":
+		return NewNativeFunction("addDays", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return NewLoxDateTime(dt.time.AddDate(0, 0, checkInteger(interpreter.callToken, arguments[0], "Days")))
+		})
+	case "addMonths":
+		return NewNativeFunction("addMonths", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return NewLoxDateTime(dt.time.AddDate(0, checkInteger(interpreter.callToken, arguments[0], "Months"), 0))
+		})
+	case "addYears":
+		return NewNativeFunction("addYears", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return NewLoxDateTime(dt.time.AddDate(checkInteger(interpreter.callToken, arguments[0], "Years"), 0, 0))
+		})
+	case "diff":
+		return NewNativeFunction("diff", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			other := interpreter.dateTimeArgument("diff", arguments, 0)
+			return float64(dt.time.Sub(other.time)) / float64(time.Millisecond)
+		})
+	case "isBefore":
+		return NewNativeFunction("isBefore", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return dt.time.Before(interpreter.dateTimeArgument("isBefore", arguments, 0).time)
+		})
+	case "isAfter":
+		return NewNativeFunction("isAfter", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return dt.time.After(interpreter.dateTimeArgument("isAfter", arguments, 0).time)
+		})
+	}
+
+	throwRuntimeError(name, fmt.Sprintf("Undefined method '%s'.", name.Lexeme))
+	return nil
+}
+
+func (dt *LoxDateTime) field(name string, value interface{}) *NativeFunction {
+	return NewNativeFunction(name, 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return value
+	})
+}
+
+func (interpreter *Interpreter) dateTimeArgument(native string, arguments []interface{}, index int) *LoxDateTime {
+	value, ok := arguments[index].(*LoxDateTime)
+	if !ok {
+		interpreter.nativeError("Argument %d of '%s' must be a DateTime.", index+1, native)
+	}
+
+	return value
+}
+
+func (interpreter *Interpreter) location(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		interpreter.nativeError("Unknown time zone '%s'.", name)
+	}
+
+	return location
+}
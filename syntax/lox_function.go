@@ -41,22 +41,25 @@ func (fun *LoxFunction) call(interpreter *Interpreter, arguments []interface{})
 
 	previous := interpreter.env
 	interpreter.env = env
+	interpreter.deferred = append(interpreter.deferred, nil)
 	func() {
 		defer func() {
 			if r := recover(); r != nil {
-				if err, ok := r.(error); ok {
-					throwRuntimeError(fun.declaration.name, err.Error())
+				ret, ok := r.(*returnValue)
+				if !ok {
+					panic(r)
 				}
 
 				if fun.isInitializer {
 					resp = fun.closure.getAt(0, "this")
 				} else {
-					resp = r
+					resp = ret.value
 				}
 			}
 		}()
+		defer interpreter.runDeferred(len(interpreter.frames))
 
-		interpreter.executeBlock(fun.declaration.body, env, nil)
+		interpreter.executeBlock(fun.declaration.body, env)
 	}()
 
 	interpreter.env = previous
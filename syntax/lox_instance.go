@@ -12,9 +12,18 @@ type LoxInstance struct {
 }
 
 func NewLoxInstance(class *LoxClass) *LoxInstance {
+	fields := make(map[string]interface{})
+	for c := class; c != nil; c = c.superclass {
+		for name, value := range c.fields {
+			if _, ok := fields[name]; !ok {
+				fields[name] = value
+			}
+		}
+	}
+
 	return &LoxInstance{
 		class:  class,
-		fields: class.fields,
+		fields: fields,
 	}
 }
 
@@ -0,0 +1,21 @@
+package syntax
+
+type LoxInterface struct {
+	interfaceName string
+	methods       map[string]*Function
+}
+
+func NewLoxInterface(name string, methods map[string]*Function) *LoxInterface {
+	return &LoxInterface{
+		interfaceName: name,
+		methods:       methods,
+	}
+}
+
+func (iface *LoxInterface) name() string {
+	return iface.interfaceName
+}
+
+func (iface *LoxInterface) String() string {
+	return iface.interfaceName
+}
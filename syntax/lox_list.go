@@ -0,0 +1,208 @@
+package syntax
+
+import (
+	"fmt"
+	"golox/scanner"
+	"sort"
+)
+
+type LoxList struct {
+	elements []interface{}
+}
+
+func NewLoxList(elements []interface{}) *LoxList {
+	return &LoxList{
+		elements: elements,
+	}
+}
+
+func (list *LoxList) get(token *scanner.Token, index interface{}) interface{} {
+	return list.elements[checkIndex(token, index, len(list.elements))]
+}
+
+func (list *LoxList) set(token *scanner.Token, index interface{}, value interface{}) {
+	list.elements[checkIndex(token, index, len(list.elements))] = value
+}
+
+func (list *LoxList) slice(token *scanner.Token, start interface{}, end interface{}) *LoxList {
+	from, to := checkSlice(token, start, end, len(list.elements))
+
+	elements := make([]interface{}, to-from)
+	copy(elements, list.elements[from:to])
+	return NewLoxList(elements)
+}
+
+func (list *LoxList) getMethod(name *scanner.Token) interface{} {
+	switch name.Lexeme {
+	case "push":
+		return NewNativeFunction("push", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			list.elements = append(list.elements, arguments[0])
+			return nil
+		})
+	case "pop":
+		return NewNativeFunction("pop", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			if len(list.elements) == 0 {
+				interpreter.nativeError("Can't pop from an empty list.")
+			}
+
+			last := list.elements[len(list.elements)-1]
+			list.elements = list.elements[:len(list.elements)-1]
+			return last
+		})
+	case "len":
+		return NewNativeFunction("len", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return float64(len(list.elements))
+		})
+	case "insert":
+		return NewNativeFunction("insert", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			index := len(list.elements)
+			if i, ok := arguments[0].(float64); !ok || i != float64(len(list.elements)) {
+				index = checkIndex(interpreter.callToken, arguments[0], len(list.elements))
+			}
+
+			list.elements = append(list.elements, nil)
+			copy(list.elements[index+1:], list.elements[index:])
+			list.elements[index] = arguments[1]
+			return nil
+		})
+	case "remove":
+		return NewNativeFunction("remove", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			index := checkIndex(interpreter.callToken, arguments[0], len(list.elements))
+
+			removed := list.elements[index]
+			list.elements = append(list.elements[:index], list.elements[index+1:]...)
+			return removed
+		})
+	case "contains":
+		return NewNativeFunction("contains", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			for _, element := range list.elements {
+				if interpreter.isEqual(element, arguments[0]) {
+					return true
+				}
+			}
+
+			return false
+		})
+	case "sort":
+		return NewNativeFunction("sort", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			if len(arguments) > 1 {
+				interpreter.nativeError("Expected 0 or 1 arguments but got %d for function 'sort'.", len(arguments))
+			}
+
+			if len(arguments) == 1 {
+				comparator := interpreter.callableArgument("sort", arguments, 0)
+				sort.SliceStable(list.elements, func(i, j int) bool {
+					result, ok := comparator.call(interpreter, []interface{}{list.elements[i], list.elements[j]}).(float64)
+					if !ok {
+						interpreter.nativeError("Comparator passed to 'sort' must return a number.")
+					}
+
+					return result < 0
+				})
+				return nil
+			}
+
+			interpreter.sortValues(list.elements)
+			return nil
+		})
+	}
+
+	throwRuntimeError(name, fmt.Sprintf("Undefined method '%s'.", name.Lexeme))
+	return nil
+}
+
+// sortValues sorts a slice that holds only numbers or only strings.
+func (interpreter *Interpreter) sortValues(values []interface{}) {
+	sorted := sortValuesBy(values, func(i int) interface{} {
+		return values[i]
+	})
+
+	if !sorted {
+		interpreter.nativeError("Can only sort lists of numbers or lists of strings without a comparator.")
+	}
+}
+
+// sortValuesBy stably sorts values by the key of each position. It reports
+// false, leaving values untouched, unless the keys are all numbers or all
+// strings.
+func sortValuesBy(values []interface{}, key func(i int) interface{}) bool {
+	numbers, strings := 0, 0
+	for i := range values {
+		switch key(i).(type) {
+		case float64:
+			numbers++
+		case string:
+			strings++
+		}
+	}
+
+	if numbers != len(values) && strings != len(values) {
+		return false
+	}
+
+	sort.SliceStable(values, func(i, j int) bool {
+		if numbers == len(values) {
+			return key(i).(float64) < key(j).(float64)
+		}
+
+		return key(i).(string) < key(j).(string)
+	})
+
+	return true
+}
+
+// checkIndex validates an index into a sequence of the given length,
+// counting negative indices from the end.
+func checkIndex(token *scanner.Token, index interface{}, length int) int {
+	i := checkInteger(token, index, "Index")
+	if i < 0 {
+		i += length
+	}
+
+	if i < 0 || i >= length {
+		throwRuntimeError(token, fmt.Sprintf("Index %s out of range for length %d.", formatNumber(index.(float64)), length))
+	}
+
+	return i
+}
+
+// checkSlice converts optional slice bounds into a half-open range, counting
+// negative bounds from the end and clamping them to the sequence.
+func checkSlice(token *scanner.Token, start interface{}, end interface{}, length int) (int, int) {
+	bound := func(value interface{}, fallback int) int {
+		if value == nil {
+			return fallback
+		}
+
+		i := checkInteger(token, value, "Slice bound")
+		if i < 0 {
+			i += length
+		}
+
+		if i < 0 {
+			return 0
+		}
+
+		if i > length {
+			return length
+		}
+
+		return i
+	}
+
+	from, to := bound(start, 0), bound(end, length)
+	if from > to {
+		from = to
+	}
+
+	return from, to
+}
+
+func checkInteger(token *scanner.Token, value interface{}, what string) int {
+	f, ok := value.(float64)
+	if !ok || f != float64(int(f)) {
+		throwRuntimeError(token, fmt.Sprintf("%s must be an integer.", what))
+	}
+
+	return int(f)
+}
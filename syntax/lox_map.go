@@ -0,0 +1,179 @@
+package syntax
+
+import (
+	"fmt"
+	"golox/scanner"
+)
+
+type mapEntry struct {
+	key   interface{}
+	value interface{}
+}
+
+// LoxMap is a hash map that keeps insertion order. Keys are bucketed by
+// Interpreter.hash and compared with Interpreter.isEqual, so instances with
+// equals()/hash() methods work as keys.
+type LoxMap struct {
+	entries []*mapEntry
+	buckets map[uint64][]*mapEntry
+	keyName string
+}
+
+func NewLoxMap() *LoxMap {
+	return &LoxMap{
+		buckets: make(map[uint64][]*mapEntry),
+		keyName: "Map keys",
+	}
+}
+
+func (m *LoxMap) find(interpreter *Interpreter, token *scanner.Token, key interface{}) (*mapEntry, uint64) {
+	checkKey(token, key, m.keyName)
+
+	hash := interpreter.hash(key, token)
+	for _, entry := range m.buckets[hash] {
+		if interpreter.isEqual(entry.key, key) {
+			return entry, hash
+		}
+	}
+
+	return nil, hash
+}
+
+func (m *LoxMap) get(interpreter *Interpreter, token *scanner.Token, key interface{}) interface{} {
+	entry, _ := m.find(interpreter, token, key)
+	if entry == nil {
+		throwRuntimeError(token, fmt.Sprintf("Undefined key %s.", interpreter.repr(key)))
+	}
+
+	return entry.value
+}
+
+func (m *LoxMap) set(interpreter *Interpreter, token *scanner.Token, key interface{}, value interface{}) {
+	entry, hash := m.find(interpreter, token, key)
+	if entry != nil {
+		entry.value = value
+		return
+	}
+
+	entry = &mapEntry{key: key, value: value}
+	m.entries = append(m.entries, entry)
+	m.buckets[hash] = append(m.buckets[hash], entry)
+}
+
+func (m *LoxMap) delete(interpreter *Interpreter, token *scanner.Token, key interface{}) bool {
+	entry, hash := m.find(interpreter, token, key)
+	if entry == nil {
+		return false
+	}
+
+	m.buckets[hash] = removeEntry(m.buckets[hash], entry)
+	if len(m.buckets[hash]) == 0 {
+		delete(m.buckets, hash)
+	}
+	m.entries = removeEntry(m.entries, entry)
+	return true
+}
+
+func (m *LoxMap) getMethod(name *scanner.Token) interface{} {
+	switch name.Lexeme {
+	case "keys":
+		return NewNativeFunction("keys", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			keys := make([]interface{}, len(m.entries))
+			for i, entry := range m.entries {
+				keys[i] = entry.key
+			}
+
+			return NewLoxList(keys)
+		})
+	case "values":
+		return NewNativeFunction("values", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			values := make([]interface{}, len(m.entries))
+			for i, entry := range m.entries {
+				values[i] = entry.value
+			}
+
+			return NewLoxList(values)
+		})
+	case "entries":
+		return NewNativeFunction("entries", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			entries := make([]interface{}, len(m.entries))
+			for i, entry := range m.entries {
+				entries[i] = NewLoxList([]interface{}{entry.key, entry.value})
+			}
+
+			return NewLoxList(entries)
+		})
+	case "has":
+		return NewNativeFunction("has", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			entry, _ := m.find(interpreter, interpreter.callToken, arguments[0])
+			return entry != nil
+		})
+	case "get":
+		return NewNativeFunction("get", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			if len(arguments) < 1 || len(arguments) > 2 {
+				interpreter.nativeError("Expected 1 or 2 arguments but got %d for function 'get'.", len(arguments))
+			}
+
+			if entry, _ := m.find(interpreter, interpreter.callToken, arguments[0]); entry != nil {
+				return entry.value
+			}
+
+			if len(arguments) == 2 {
+				return arguments[1]
+			}
+
+			return nil
+		})
+	case "delete":
+		return NewNativeFunction("delete", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return m.delete(interpreter, interpreter.callToken, arguments[0])
+		})
+	case "len":
+		return NewNativeFunction("len", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return float64(len(m.entries))
+		})
+	}
+
+	throwRuntimeError(name, fmt.Sprintf("Undefined method '%s'.", name.Lexeme))
+	return nil
+}
+
+// checkKey reports a runtime error for keys that can't be hashed. Sets can
+// still be modified, so only frozen sets are accepted.
+func checkKey(token *scanner.Token, key interface{}, keyName string) {
+	switch key.(type) {
+	case string, float64, bool, *LoxInstance, *LoxTuple, *LoxSet:
+		if !containsMutableSet(key) {
+			return
+		}
+
+		throwRuntimeError(token, fmt.Sprintf("%s can't contain mutable sets. Use freeze() to get a frozen copy.", keyName))
+	}
+
+	throwRuntimeError(token, fmt.Sprintf("%s must be strings, numbers, booleans, instances, tuples or frozen sets.", keyName))
+}
+
+func containsMutableSet(value interface{}) bool {
+	switch val := value.(type) {
+	case *LoxSet:
+		return !val.frozen
+	case *LoxTuple:
+		for _, element := range val.elements {
+			if containsMutableSet(element) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func removeEntry(entries []*mapEntry, entry *mapEntry) []*mapEntry {
+	for i, e := range entries {
+		if e == entry {
+			return append(entries[:i], entries[i+1:]...)
+		}
+	}
+
+	return entries
+}
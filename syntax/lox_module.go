@@ -0,0 +1,215 @@
+package syntax
+
+import (
+	"fmt"
+	"golox/loxerror"
+	"golox/scanner"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoxModule is the value an import binds: a file's top-level environment,
+// of which only the exported names are visible to the importer.
+type LoxModule struct {
+	moduleName string
+	path       string
+	env        *Environment
+	exports    map[string]bool
+	withheld   map[string]bool
+}
+
+func NewLoxModule(name string, path string, env *Environment) *LoxModule {
+	return &LoxModule{
+		moduleName: name,
+		path:       path,
+		env:        env,
+		exports:    make(map[string]bool),
+	}
+}
+
+// NewNativeModule builds a module that exports the given natives, for the
+// standard library modules defined in Go.
+func NewNativeModule(name string, natives ...*NativeFunction) *LoxModule {
+	module := NewLoxModule(name, "", NewEnvironment(nil))
+	for _, native := range natives {
+		module.define(native.nativeName, native)
+	}
+
+	return module
+}
+
+// define adds an exported value to the module.
+func (module *LoxModule) define(name string, value interface{}) {
+	module.env.define(name, value)
+	module.exports[name] = true
+}
+
+func (module *LoxModule) get(name *scanner.Token) interface{} {
+	if !module.exports[name.Lexeme] {
+		if module.withheld[name.Lexeme] {
+			throwRuntimeError(name, fmt.Sprintf("'%s.%s' is not allowed in this interpreter.", module.moduleName, name.Lexeme))
+		}
+
+		throwRuntimeError(name, fmt.Sprintf("Module '%s' has no export '%s'.", module.moduleName, name.Lexeme))
+	}
+
+	return module.env.values[name.Lexeme]
+}
+
+func (module *LoxModule) String() string {
+	return fmt.Sprintf("<module %s>", module.moduleName)
+}
+
+// SetScriptPath records the file the main program was read from, which
+// relative imports are resolved against.
+func (interpreter *Interpreter) SetScriptPath(path string) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	interpreter.module.path = path
+	interpreter.modules[path] = interpreter.module
+	interpreter.importing = []string{path}
+}
+
+func (interpreter *Interpreter) visitImportCmdStmt(stmt *ImportCmd) interface{} {
+	interpreter.requireCapability(stmt.keyword, IMPORT, "Importing modules")
+	interpreter.env.define(stmt.name.Lexeme, interpreter.importModule(stmt.path))
+	return nil
+}
+
+func (interpreter *Interpreter) visitExportStmt(stmt *Export) interface{} {
+	interpreter.execute(stmt.declaration)
+
+	switch decl := stmt.declaration.(type) {
+	case *Function:
+		interpreter.module.exports[decl.name.Lexeme] = true
+	case *Class:
+		interpreter.module.exports[decl.name.Lexeme] = true
+	case *InterfaceCmd:
+		interpreter.module.exports[decl.name.Lexeme] = true
+	case *Trait:
+		interpreter.module.exports[decl.name.Lexeme] = true
+	case *VarCmd:
+		interpreter.module.exports[decl.name.Lexeme] = true
+	}
+
+	return nil
+}
+
+// importModule returns the module at the path named by token, running the
+// file the first time it is imported.
+func (interpreter *Interpreter) importModule(token *scanner.Token) *LoxModule {
+	path := interpreter.findModule(token)
+
+	for i, p := range interpreter.importing {
+		if p == path {
+			var names []string
+			for _, p := range append(interpreter.importing[i:], path) {
+				names = append(names, filepath.Base(p))
+			}
+
+			throwRuntimeError(token, fmt.Sprintf("Import cycle: %s.", strings.Join(names, " -> ")))
+		}
+	}
+
+	if module, ok := interpreter.modules[path]; ok {
+		return module
+	}
+
+	return interpreter.loadModule(token, path)
+}
+
+// findModule resolves an import path relative to the importing file, then
+// against each directory in LOXPATH.
+func (interpreter *Interpreter) findModule(token *scanner.Token) string {
+	name := token.Literal.(string)
+
+	var candidates []string
+	if filepath.IsAbs(name) {
+		candidates = append(candidates, name)
+	} else {
+		dir := "."
+		if interpreter.module.path != "" {
+			dir = filepath.Dir(interpreter.module.path)
+		}
+		candidates = append(candidates, filepath.Join(dir, name))
+
+		for _, dir := range filepath.SplitList(os.Getenv("LOXPATH")) {
+			if dir != "" {
+				candidates = append(candidates, filepath.Join(dir, name))
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			if abs, err := filepath.Abs(candidate); err == nil {
+				return abs
+			}
+			return candidate
+		}
+	}
+
+	throwRuntimeError(token, fmt.Sprintf("Could not find module '%s'.", name))
+	return ""
+}
+
+func (interpreter *Interpreter) loadModule(token *scanner.Token, path string) *LoxModule {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		throwRuntimeError(token, fmt.Sprintf("Could not read module '%s': %s", token.Literal, err.Error()))
+	}
+
+	// Class names are tracked per file while parsing, so a module can
+	// declare classes with the same names as its importer.
+	parser := NewAstParser(scanner.NewScanner(string(data)).ScanTokens(), interpreter)
+	parser.declaredClasses, parser.declaredTypes = copyNames(interpreter.builtinClasses), map[string]bool{}
+	statements := parser.Parse()
+
+	if loxerror.HadError() {
+		throwRuntimeError(token, fmt.Sprintf("Module '%s' has errors.", token.Literal))
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	module := NewLoxModule(name, path, NewEnvironment(interpreter.globals))
+	interpreter.modules[path] = module
+
+	previousEnv, previousModule := interpreter.env, interpreter.module
+	interpreter.env, interpreter.module = module.env, module
+	interpreter.importing = append(interpreter.importing, path)
+	defer func() {
+		interpreter.env, interpreter.module = previousEnv, previousModule
+		interpreter.importing = interpreter.importing[:len(interpreter.importing)-1]
+
+		// A module that failed to load is retried on the next import.
+		if r := recover(); r != nil {
+			delete(interpreter.modules, path)
+			panic(r)
+		}
+	}()
+
+	// The module is resolved against its own environment, so it only sees
+	// the globals and not the names of the module importing it.
+	NewResolver(interpreter).Resolve(statements)
+	if loxerror.HadError() {
+		throwRuntimeError(token, fmt.Sprintf("Module '%s' has errors.", token.Literal))
+	}
+
+	for _, stmt := range statements {
+		interpreter.execute(stmt)
+	}
+
+	return module
+}
+
+func copyNames(names map[string]bool) map[string]bool {
+	copied := make(map[string]bool, len(names))
+	for name := range names {
+		copied[name] = true
+	}
+
+	return copied
+}
@@ -0,0 +1,136 @@
+package syntax
+
+import (
+	"fmt"
+	"golox/scanner"
+	"math"
+)
+
+// maxRangeList is the most elements toList will allocate for a range.
+const maxRangeList = 1 << 24
+
+// LoxRange is a lazy arithmetic sequence created by 'start..end' (inclusive)
+// or 'start..<end' (exclusive), optionally followed by 'step n'.
+type LoxRange struct {
+	start     float64
+	end       float64
+	step      float64
+	inclusive bool
+}
+
+func NewLoxRange(start float64, end float64, step float64, inclusive bool) *LoxRange {
+	return &LoxRange{
+		start:     start,
+		end:       end,
+		step:      step,
+		inclusive: inclusive,
+	}
+}
+
+// len counts the values in the range. A range with an infinite end, such as
+// '0..inf', has an infinite length rather than being an error, so it can
+// still be iterated lazily; that is also why len is a float64.
+func (r *LoxRange) len() float64 {
+	span := (r.end - r.start) / r.step
+
+	var n float64
+	if r.inclusive {
+		n = math.Floor(span) + 1
+	} else {
+		n = math.Ceil(span)
+	}
+
+	return math.Max(n, 0)
+}
+
+func (r *LoxRange) at(i int) float64 {
+	return r.start + float64(i)*r.step
+}
+
+func (r *LoxRange) contains(value interface{}) bool {
+	f, ok := value.(float64)
+	if !ok {
+		return false
+	}
+
+	k := (f - r.start) / r.step
+	return k == math.Trunc(k) && k >= 0 && k < r.len()
+}
+
+// positions returns the indices a range selects when used to index a
+// sequence of the given length. Like a slice, it drops positions that fall
+// outside the sequence instead of reporting them.
+func (r *LoxRange) positions(token *scanner.Token, length int) []int {
+	low, high := float64(-length), float64(length)
+
+	// Skip ahead to the first value that can be inside the sequence, so a
+	// range like '-1e18..2' doesn't walk through every position before it.
+	var first float64
+	if r.step > 0 && r.start < low {
+		first = math.Ceil((low - r.start) / r.step)
+	} else if r.step < 0 && r.start >= high {
+		first = math.Ceil((r.start - high + 1) / -r.step)
+	}
+
+	var positions []int
+	for i := first; i < r.len(); i++ {
+		value := r.start + i*r.step
+		if (r.step > 0 && value >= high) || (r.step < 0 && value < low) {
+			break
+		}
+
+		position := checkInteger(token, value, "Index")
+		if position < 0 {
+			position += length
+		}
+
+		if position >= 0 && position < length {
+			positions = append(positions, position)
+		}
+	}
+
+	return positions
+}
+
+func (r *LoxRange) getMethod(name *scanner.Token) interface{} {
+	switch name.Lexeme {
+	case "contains":
+		return NewNativeFunction("contains", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return r.contains(arguments[0])
+		})
+	case "len":
+		return NewNativeFunction("len", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return r.len()
+		})
+	case "toList":
+		return NewNativeFunction("toList", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			if r.len() > maxRangeList {
+				interpreter.nativeError("Range %s has too many elements to convert to a list.", r)
+			}
+
+			elements := make([]interface{}, int(r.len()))
+			for i := range elements {
+				elements[i] = r.at(i)
+			}
+
+			return NewLoxList(elements)
+		})
+	}
+
+	throwRuntimeError(name, fmt.Sprintf("Undefined method '%s'.", name.Lexeme))
+	return nil
+}
+
+func (r *LoxRange) String() string {
+	operator := "..<"
+	if r.inclusive {
+		operator = ".."
+	}
+
+	s := fmt.Sprintf("%s%s%s", formatNumber(r.start), operator, formatNumber(r.end))
+	if r.step != 1 {
+		s += " step " + formatNumber(r.step)
+	}
+
+	return s
+}
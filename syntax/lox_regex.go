@@ -0,0 +1,154 @@
+package syntax
+
+import (
+	"fmt"
+	"golox/scanner"
+	"regexp"
+)
+
+// LoxRegex is a compiled regular expression using Go's RE2 syntax. Matches
+// are lists holding the whole match followed by each capture group, with
+// nil for groups that did not participate.
+type LoxRegex struct {
+	regexp *regexp.Regexp
+}
+
+func NewLoxRegex(re *regexp.Regexp) *LoxRegex {
+	return &LoxRegex{
+		regexp: re,
+	}
+}
+
+func (re *LoxRegex) String() string {
+	return fmt.Sprintf("/%s/", re.regexp.String())
+}
+
+func (re *LoxRegex) getMethod(name *scanner.Token) interface{} {
+	switch name.Lexeme {
+	case "pattern":
+		return NewNativeFunction("pattern", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return re.regexp.String()
+		})
+	case "match":
+		return NewNativeFunction("match", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return re.regexp.MatchString(interpreter.stringArgument("match", arguments, 0))
+		})
+	case "find":
+		return NewNativeFunction("find", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			s := interpreter.stringArgument("find", arguments, 0)
+
+			indexes := re.regexp.FindStringSubmatchIndex(s)
+			if indexes == nil {
+				return nil
+			}
+
+			return re.groups(s, indexes)
+		})
+	case "findAll":
+		return NewNativeFunction("findAll", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			interpreter.checkArity("findAll", arguments, 1, 2)
+			s := interpreter.stringArgument("findAll", arguments, 0)
+
+			limit := -1
+			if len(arguments) == 2 {
+				limit = checkInteger(interpreter.callToken, arguments[1], "Limit")
+			}
+
+			var matches []interface{}
+			for _, indexes := range re.regexp.FindAllStringSubmatchIndex(s, limit) {
+				matches = append(matches, re.groups(s, indexes))
+			}
+
+			return NewLoxList(matches)
+		})
+	case "findNamed":
+		return NewNativeFunction("findNamed", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			s := interpreter.stringArgument("findNamed", arguments, 0)
+
+			indexes := re.regexp.FindStringSubmatchIndex(s)
+			if indexes == nil {
+				return nil
+			}
+
+			groups := re.groups(s, indexes).elements
+			named := NewLoxMap()
+			for i, name := range re.regexp.SubexpNames() {
+				if name != "" {
+					named.set(interpreter, interpreter.callToken, name, groups[i])
+				}
+			}
+
+			return named
+		})
+	case "replace":
+		return NewNativeFunction("replace", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			s := interpreter.stringArgument("replace", arguments, 0)
+
+			if replacement, ok := arguments[1].(string); ok {
+				return re.regexp.ReplaceAllString(s, replacement)
+			}
+
+			callback := interpreter.callableArgument("replace", arguments, 1)
+			if callback.arity() != 1 {
+				interpreter.nativeError("Replacement function for 'replace' must take 1 argument.")
+			}
+
+			// The callback receives the match list and its result, converted
+			// to a string, replaces the match.
+			var out []byte
+			last := 0
+			for _, indexes := range re.regexp.FindAllStringSubmatchIndex(s, -1) {
+				out = append(out, s[last:indexes[0]]...)
+				out = append(out, interpreter.stringify(callback.call(interpreter, []interface{}{re.groups(s, indexes)}))...)
+				last = indexes[1]
+			}
+
+			return string(append(out, s[last:]...))
+		})
+	case "split":
+		return NewNativeFunction("split", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			interpreter.checkArity("split", arguments, 1, 2)
+			s := interpreter.stringArgument("split", arguments, 0)
+
+			limit := -1
+			if len(arguments) == 2 {
+				limit = checkInteger(interpreter.callToken, arguments[1], "Limit")
+			}
+
+			parts := re.regexp.Split(s, limit)
+			elements := make([]interface{}, len(parts))
+			for i, part := range parts {
+				elements[i] = part
+			}
+
+			return NewLoxList(elements)
+		})
+	}
+
+	throwRuntimeError(name, fmt.Sprintf("Undefined method '%s'.", name.Lexeme))
+	return nil
+}
+
+func (re *LoxRegex) groups(s string, indexes []int) *LoxList {
+	groups := make([]interface{}, len(indexes)/2)
+	for i := range groups {
+		if indexes[2*i] >= 0 {
+			groups[i] = s[indexes[2*i]:indexes[2*i+1]]
+		}
+	}
+
+	return NewLoxList(groups)
+}
+
+func defineRegex(env *Environment) {
+	env.define("regex", NewNativeFunction("regex", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		pattern := interpreter.stringArgument("regex", arguments, 0)
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			interpreter.nativeError("Invalid regex '%s': %s", pattern, err.Error())
+		}
+
+		return NewLoxRegex(re)
+	}))
+}
@@ -0,0 +1,167 @@
+package syntax
+
+import (
+	"fmt"
+	"golox/scanner"
+)
+
+// LoxSet is an insertion-ordered set backed by a LoxMap. Its hash only
+// depends on its contents, so only frozen sets, which can't be modified,
+// are accepted as map keys.
+type LoxSet struct {
+	items  *LoxMap
+	frozen bool
+}
+
+func NewLoxSet(frozen bool) *LoxSet {
+	items := NewLoxMap()
+	items.keyName = "Set elements"
+
+	return &LoxSet{
+		items:  items,
+		frozen: frozen,
+	}
+}
+
+func (set *LoxSet) add(interpreter *Interpreter, token *scanner.Token, value interface{}) {
+	set.items.set(interpreter, token, value, nil)
+}
+
+func (set *LoxSet) checkMutable(token *scanner.Token) {
+	if set.frozen {
+		throwRuntimeError(token, "Can't modify a frozen set.")
+	}
+}
+
+func (set *LoxSet) has(interpreter *Interpreter, token *scanner.Token, value interface{}) bool {
+	entry, _ := set.items.find(interpreter, token, value)
+	return entry != nil
+}
+
+func (set *LoxSet) elements() []interface{} {
+	elements := make([]interface{}, len(set.items.entries))
+	for i, entry := range set.items.entries {
+		elements[i] = entry.key
+	}
+
+	return elements
+}
+
+func (set *LoxSet) getMethod(name *scanner.Token) interface{} {
+	switch name.Lexeme {
+	case "add":
+		return NewNativeFunction("add", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			set.checkMutable(interpreter.callToken)
+			set.add(interpreter, interpreter.callToken, arguments[0])
+			return nil
+		})
+	case "remove":
+		return NewNativeFunction("remove", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			set.checkMutable(interpreter.callToken)
+			return set.items.delete(interpreter, interpreter.callToken, arguments[0])
+		})
+	case "has", "contains":
+		return NewNativeFunction(name.Lexeme, 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return set.has(interpreter, interpreter.callToken, arguments[0])
+		})
+	case "len":
+		return NewNativeFunction("len", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return float64(len(set.items.entries))
+		})
+	case "freeze":
+		return NewNativeFunction("freeze", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			if set.frozen {
+				return set
+			}
+
+			frozen := NewLoxSet(true)
+			for _, element := range set.elements() {
+				frozen.add(interpreter, interpreter.callToken, element)
+			}
+
+			return frozen
+		})
+	case "isFrozen":
+		return NewNativeFunction("isFrozen", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return set.frozen
+		})
+	case "toList":
+		return NewNativeFunction("toList", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return NewLoxList(set.elements())
+		})
+	case "union":
+		return NewNativeFunction("union", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			other := interpreter.setArgument("union", arguments, 0)
+
+			result := NewLoxSet(set.frozen)
+			for _, element := range append(set.elements(), other.elements()...) {
+				result.add(interpreter, interpreter.callToken, element)
+			}
+
+			return result
+		})
+	case "intersection":
+		return NewNativeFunction("intersection", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			other := interpreter.setArgument("intersection", arguments, 0)
+
+			result := NewLoxSet(set.frozen)
+			for _, element := range set.elements() {
+				if other.has(interpreter, interpreter.callToken, element) {
+					result.add(interpreter, interpreter.callToken, element)
+				}
+			}
+
+			return result
+		})
+	case "difference":
+		return NewNativeFunction("difference", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			other := interpreter.setArgument("difference", arguments, 0)
+
+			result := NewLoxSet(set.frozen)
+			for _, element := range set.elements() {
+				if !other.has(interpreter, interpreter.callToken, element) {
+					result.add(interpreter, interpreter.callToken, element)
+				}
+			}
+
+			return result
+		})
+	}
+
+	throwRuntimeError(name, fmt.Sprintf("Undefined method '%s'.", name.Lexeme))
+	return nil
+}
+
+func (interpreter *Interpreter) setArgument(native string, arguments []interface{}, index int) *LoxSet {
+	value, ok := arguments[index].(*LoxSet)
+	if !ok {
+		interpreter.nativeError("Argument %d of '%s' must be a set.", index+1, native)
+	}
+
+	return value
+}
+
+func defineCollections(env *Environment) {
+	env.define("tuple", NewNativeFunction("tuple", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		elements := make([]interface{}, len(arguments))
+		copy(elements, arguments)
+		return NewLoxTuple(elements)
+	}))
+
+	env.define("set", NewNativeFunction("set", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return newSetOf(interpreter, arguments, false)
+	}))
+
+	env.define("frozenSet", NewNativeFunction("frozenSet", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return newSetOf(interpreter, arguments, true)
+	}))
+}
+
+func newSetOf(interpreter *Interpreter, elements []interface{}, frozen bool) *LoxSet {
+	set := NewLoxSet(frozen)
+	for _, element := range elements {
+		set.add(interpreter, interpreter.callToken, element)
+	}
+
+	return set
+}
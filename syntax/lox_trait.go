@@ -0,0 +1,30 @@
+package syntax
+
+import "golox/scanner"
+
+type LoxTrait struct {
+	traitName string
+	methods   map[string]*LoxFunction
+}
+
+func NewLoxTrait(name string, methods map[string]*LoxFunction) *LoxTrait {
+	return &LoxTrait{
+		traitName: name,
+		methods:   methods,
+	}
+}
+
+func (trait *LoxTrait) name() string {
+	return trait.traitName
+}
+
+func (trait *LoxTrait) String() string {
+	return trait.traitName
+}
+
+// TraitUse is a 'use Trait.method;' clause in a class body. It picks which
+// trait's method the class takes when several of its traits provide one.
+type TraitUse struct {
+	trait  *scanner.Token
+	method *scanner.Token
+}
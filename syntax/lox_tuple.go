@@ -0,0 +1,58 @@
+package syntax
+
+import (
+	"fmt"
+	"golox/scanner"
+)
+
+// LoxTuple is an immutable sequence. Tuples compare and hash by their
+// elements, so they can be used as composite map keys.
+type LoxTuple struct {
+	elements []interface{}
+}
+
+func NewLoxTuple(elements []interface{}) *LoxTuple {
+	return &LoxTuple{
+		elements: elements,
+	}
+}
+
+func (tuple *LoxTuple) get(token *scanner.Token, index interface{}) interface{} {
+	return tuple.elements[checkIndex(token, index, len(tuple.elements))]
+}
+
+func (tuple *LoxTuple) slice(token *scanner.Token, start interface{}, end interface{}) *LoxTuple {
+	from, to := checkSlice(token, start, end, len(tuple.elements))
+
+	elements := make([]interface{}, to-from)
+	copy(elements, tuple.elements[from:to])
+	return NewLoxTuple(elements)
+}
+
+func (tuple *LoxTuple) getMethod(name *scanner.Token) interface{} {
+	switch name.Lexeme {
+	case "len":
+		return NewNativeFunction("len", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return float64(len(tuple.elements))
+		})
+	case "contains":
+		return NewNativeFunction("contains", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			for _, element := range tuple.elements {
+				if interpreter.isEqual(element, arguments[0]) {
+					return true
+				}
+			}
+
+			return false
+		})
+	case "toList":
+		return NewNativeFunction("toList", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			elements := make([]interface{}, len(tuple.elements))
+			copy(elements, tuple.elements)
+			return NewLoxList(elements)
+		})
+	}
+
+	throwRuntimeError(name, fmt.Sprintf("Undefined method '%s'.", name.Lexeme))
+	return nil
+}
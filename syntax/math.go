@@ -0,0 +1,110 @@
+package syntax
+
+import (
+	"math"
+	"math/rand"
+)
+
+// defineMath defines the math module. The random functions share one source
+// per interpreter, which seed() resets so scripts and tests can be
+// deterministic.
+func defineMath(env *Environment) {
+	module := NewNativeModule("math",
+		unaryMath("sqrt", math.Sqrt),
+		unaryMath("abs", math.Abs),
+		unaryMath("floor", math.Floor),
+		unaryMath("ceil", math.Ceil),
+		unaryMath("round", math.Round),
+		unaryMath("trunc", math.Trunc),
+		unaryMath("sin", math.Sin),
+		unaryMath("cos", math.Cos),
+		unaryMath("tan", math.Tan),
+		unaryMath("asin", math.Asin),
+		unaryMath("acos", math.Acos),
+		unaryMath("atan", math.Atan),
+		unaryMath("exp", math.Exp),
+		unaryMath("log", math.Log),
+		unaryMath("log2", math.Log2),
+		unaryMath("log10", math.Log10),
+
+		NewNativeFunction("pow", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return math.Pow(interpreter.numberArgument("pow", arguments, 0), interpreter.numberArgument("pow", arguments, 1))
+		}),
+
+		NewNativeFunction("atan2", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return math.Atan2(interpreter.numberArgument("atan2", arguments, 0), interpreter.numberArgument("atan2", arguments, 1))
+		}),
+
+		NewNativeFunction("min", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			interpreter.checkArity("min", arguments, 1, 256)
+
+			min := interpreter.numberArgument("min", arguments, 0)
+			for i := range arguments[1:] {
+				min = math.Min(min, interpreter.numberArgument("min", arguments, i+1))
+			}
+
+			return min
+		}),
+
+		NewNativeFunction("max", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			interpreter.checkArity("max", arguments, 1, 256)
+
+			max := interpreter.numberArgument("max", arguments, 0)
+			for i := range arguments[1:] {
+				max = math.Max(max, interpreter.numberArgument("max", arguments, i+1))
+			}
+
+			return max
+		}),
+
+		NewNativeFunction("isNaN", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return math.IsNaN(interpreter.numberArgument("isNaN", arguments, 0))
+		}),
+
+		NewNativeFunction("seed", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			seed := checkInteger(interpreter.callToken, arguments[0], "Seed")
+			interpreter.random = rand.New(rand.NewSource(int64(seed)))
+			return nil
+		}),
+
+		NewNativeFunction("random", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return interpreter.random.Float64()
+		}),
+
+		NewNativeFunction("randInt", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			low := checkInteger(interpreter.callToken, arguments[0], "Lower bound")
+			high := checkInteger(interpreter.callToken, arguments[1], "Upper bound")
+			if low > high {
+				interpreter.nativeError("Lower bound %d is greater than upper bound %d.", low, high)
+			}
+
+			return float64(low + interpreter.random.Intn(high-low+1))
+		}),
+
+		NewNativeFunction("shuffle", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			list, ok := arguments[0].(*LoxList)
+			if !ok {
+				interpreter.nativeError("Argument 1 of 'shuffle' must be a list.")
+			}
+
+			interpreter.random.Shuffle(len(list.elements), func(i, j int) {
+				list.elements[i], list.elements[j] = list.elements[j], list.elements[i]
+			})
+
+			return nil
+		}),
+	)
+
+	module.define("pi", math.Pi)
+	module.define("e", math.E)
+	module.define("inf", math.Inf(1))
+	module.define("nan", math.NaN())
+
+	env.define("math", module)
+}
+
+func unaryMath(name string, function func(float64) float64) *NativeFunction {
+	return NewNativeFunction(name, 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return function(interpreter.numberArgument(name, arguments, 0))
+	})
+}
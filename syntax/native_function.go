@@ -0,0 +1,83 @@
+package syntax
+
+import (
+	"fmt"
+	"golox/references"
+)
+
+// NativeFunction is a LoxCallable implemented in Go. An arity of -1 marks a
+// variadic native that checks its own arguments.
+type NativeFunction struct {
+	nativeName  string
+	nativeArity int
+	function    func(interpreter *Interpreter, arguments []interface{}) interface{}
+}
+
+func NewNativeFunction(name string, arity int, function func(interpreter *Interpreter, arguments []interface{}) interface{}) *NativeFunction {
+	return &NativeFunction{
+		nativeName:  name,
+		nativeArity: arity,
+		function:    function,
+	}
+}
+
+func (native *NativeFunction) call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	return native.function(interpreter, arguments)
+}
+
+func (native *NativeFunction) arity() int {
+	return native.nativeArity
+}
+
+func (native *NativeFunction) name() string {
+	return native.nativeName
+}
+
+func (native *NativeFunction) callableType() references.FunctionType {
+	return references.Function
+}
+
+func (native *NativeFunction) String() string {
+	return "<native fn>"
+}
+
+// nativeError reports a runtime error at the call site of the native that is
+// currently executing.
+func (interpreter *Interpreter) nativeError(format string, args ...interface{}) {
+	throwRuntimeError(interpreter.callToken, fmt.Sprintf(format, args...))
+}
+
+// checkArity checks the argument count of a variadic native that takes
+// between min and max arguments.
+func (interpreter *Interpreter) checkArity(native string, arguments []interface{}, min int, max int) {
+	if len(arguments) < min || len(arguments) > max {
+		interpreter.nativeError("Expected %d to %d arguments but got %d for function '%s'.", min, max, len(arguments), native)
+	}
+}
+
+func (interpreter *Interpreter) stringArgument(native string, arguments []interface{}, index int) string {
+	value, ok := arguments[index].(string)
+	if !ok {
+		interpreter.nativeError("Argument %d of '%s' must be a string.", index+1, native)
+	}
+
+	return value
+}
+
+func (interpreter *Interpreter) numberArgument(native string, arguments []interface{}, index int) float64 {
+	value, ok := arguments[index].(float64)
+	if !ok {
+		interpreter.nativeError("Argument %d of '%s' must be a number.", index+1, native)
+	}
+
+	return value
+}
+
+func (interpreter *Interpreter) callableArgument(native string, arguments []interface{}, index int) LoxCallable {
+	value, ok := arguments[index].(LoxCallable)
+	if !ok {
+		interpreter.nativeError("Argument %d of '%s' must be a function.", index+1, native)
+	}
+
+	return value
+}
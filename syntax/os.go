@@ -0,0 +1,102 @@
+package syntax
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+)
+
+// Exit is raised by os.exit(). It can't be caught by scripts, and Interpret
+// passes it on to its caller, which should exit the process with Code.
+type Exit struct {
+	Code int
+}
+
+// defineOS defines the os module for the environment, the working directory
+// and running other programs. The script's own arguments are os.args.
+func (interpreter *Interpreter) defineOS() {
+	module := interpreter.newHostModule("os",
+		requires(ENV, NewNativeFunction("env", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			value, ok := os.LookupEnv(interpreter.stringArgument("env", arguments, 0))
+			if !ok {
+				return nil
+			}
+
+			return value
+		})),
+
+		requires(ENV, NewNativeFunction("setEnv", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			name := interpreter.stringArgument("setEnv", arguments, 0)
+
+			if err := os.Setenv(name, interpreter.stringArgument("setEnv", arguments, 1)); err != nil {
+				interpreter.nativeError("Could not set environment variable '%s': %s", name, err.Error())
+			}
+
+			return nil
+		})),
+
+		// exit unwinds the script instead of stopping the process directly,
+		// so finally blocks and deferred calls still run.
+		requires(PROCESS, NewNativeFunction("exit", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			panic(&Exit{Code: checkInteger(interpreter.callToken, arguments[0], "Exit code")})
+		})),
+
+		requires(FS_READ, NewNativeFunction("cwd", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			dir, err := os.Getwd()
+			if err != nil {
+				interpreter.nativeError("Could not get working directory: %s", err.Error())
+			}
+
+			return dir
+		})),
+
+		// exec runs a program to completion and returns a map with its
+		// stdout, stderr and exit code. Only failing to start it is an error.
+		requires(PROCESS, NewNativeFunction("exec", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			interpreter.checkArity("exec", arguments, 1, 2)
+			name := interpreter.stringArgument("exec", arguments, 0)
+
+			var args []string
+			if len(arguments) == 2 {
+				interpreter.iterate(interpreter.callToken, arguments[1], func(item interface{}) bool {
+					args = append(args, interpreter.stringify(item))
+					return true
+				})
+			}
+
+			var stdout, stderr bytes.Buffer
+			cmd := exec.Command(name, args...)
+			cmd.Stdout = &stdout
+			cmd.Stderr = &stderr
+
+			code := 0
+			if err := cmd.Run(); err != nil {
+				exitErr, ok := err.(*exec.ExitError)
+				if !ok {
+					interpreter.nativeError("Could not run '%s': %s", name, err.Error())
+				}
+
+				code = exitErr.ExitCode()
+			}
+
+			result := NewLoxMap()
+			result.set(interpreter, interpreter.callToken, "stdout", stdout.String())
+			result.set(interpreter, interpreter.callToken, "stderr", stderr.String())
+			result.set(interpreter, interpreter.callToken, "code", float64(code))
+			return result
+		})),
+	)
+
+	module.define("args", NewLoxList(nil))
+	interpreter.globals.define("os", module)
+}
+
+// SetArgs sets the arguments the script sees as os.args.
+func (interpreter *Interpreter) SetArgs(args []string) {
+	elements := make([]interface{}, len(args))
+	for i, arg := range args {
+		elements[i] = arg
+	}
+
+	interpreter.globals.values["os"].(*LoxModule).define("args", NewLoxList(elements))
+}
@@ -7,18 +7,24 @@ import (
 	"golox/scanner"
 )
 
-var declaredClasses map[string]bool = map[string]bool{}
-var staticContext bool = false
-
 type AstParser struct {
 	Tokens  []*scanner.Token
 	Current int
+
+	declaredClasses map[string]bool
+	declaredTypes   map[string]bool
+	staticContext   bool
 }
 
-func NewAstParser(tokens []*scanner.Token) *AstParser {
+// NewAstParser creates a parser for a script run by the interpreter. Class
+// and type names are recorded in the interpreter, so later scripts, such as
+// REPL lines, know about the classes declared before them.
+func NewAstParser(tokens []*scanner.Token, interpreter *Interpreter) *AstParser {
 	return &AstParser{
-		Tokens:  tokens,
-		Current: 0,
+		Tokens:          tokens,
+		Current:         0,
+		declaredClasses: interpreter.declaredClasses,
+		declaredTypes:   interpreter.declaredTypes,
 	}
 }
 
@@ -38,8 +44,29 @@ func (parser *AstParser) declaration() Stmt {
 		}
 	}()
 
+	if parser.match(references.Import) {
+		return parser.importDeclaration()
+	}
+
+	if parser.match(references.Export) {
+		return parser.exportDeclaration()
+	}
+
 	if parser.match(references.Class) {
-		return parser.classDeclaration()
+		return parser.classDeclaration(false)
+	}
+
+	if parser.match(references.Abstract) {
+		parser.consume(references.Class, "Expect 'class' after 'abstract'.")
+		return parser.classDeclaration(true)
+	}
+
+	if parser.match(references.Interface) {
+		return parser.interfaceDeclaration()
+	}
+
+	if parser.match(references.Trait) {
+		return parser.traitDeclaration()
 	}
 
 	if parser.match(references.Fun) {
@@ -53,7 +80,36 @@ func (parser *AstParser) declaration() Stmt {
 	return parser.statement()
 }
 
-func (parser *AstParser) classDeclaration() Stmt {
+// importDeclaration parses 'import "path" as name;'. 'as' is only a keyword
+// in this position.
+func (parser *AstParser) importDeclaration() Stmt {
+	keyword := parser.previous()
+	path := parser.consume(references.String, "Expect module path after 'import'.")
+
+	if !parser.check(references.Identifier) || parser.peek().Lexeme != "as" {
+		throwError(parser.peek(), "Expect 'as' after module path.")
+	}
+	parser.advance()
+
+	name := parser.consume(references.Identifier, "Expect module name after 'as'.")
+	parser.consume(references.Semicolon, "Expect ';' after import.")
+
+	return NewImportCmd(keyword, path, name)
+}
+
+func (parser *AstParser) exportDeclaration() Stmt {
+	keyword := parser.previous()
+
+	switch parser.peek().Type {
+	case references.Class, references.Abstract, references.Interface, references.Trait, references.Fun, references.Var:
+	default:
+		throwError(parser.peek(), "Expect declaration after 'export'.")
+	}
+
+	return NewExport(keyword, parser.declaration())
+}
+
+func (parser *AstParser) classDeclaration(isAbstract bool) Stmt {
 	name := parser.consume(references.Identifier, "Expect class name.")
 
 	var superclass *Variable
@@ -62,31 +118,143 @@ func (parser *AstParser) classDeclaration() Stmt {
 		superclass = NewVariable(parser.previous(), references.Klass).(*Variable)
 	}
 
+	var traits []*Variable
+	if parser.match(references.With) {
+		for ok := true; ok; ok = parser.match(references.Comma) {
+			parser.consume(references.Identifier, "Expect trait name.")
+			traits = append(traits, NewVariable(parser.previous(), references.Klass).(*Variable))
+		}
+	}
+
+	var interfaces []*Variable
+	if parser.match(references.Implements) {
+		for ok := true; ok; ok = parser.match(references.Comma) {
+			parser.consume(references.Identifier, "Expect interface name.")
+			interfaces = append(interfaces, NewVariable(parser.previous(), references.Klass).(*Variable))
+		}
+	}
+
 	parser.consume(references.LeftBrace, "Expect '{' before class body.")
 
 	var methods []*Function
 	var fields []*VarCmd
+	var uses []*TraitUse
 	for !parser.check(references.RightBrace) && !parser.isAtEnd() {
+		if parser.isUseClause() {
+			uses = append(uses, parser.traitUse(traits))
+			continue
+		}
+
 		method := parser.function("method")
 		if method == nil {
 			fields = append(fields, parser.varDeclaration().(*VarCmd))
 		} else {
-			methods = append(methods, method.(*Function))
+			function := method.(*Function)
+			if function.isAbstract && !isAbstract {
+				throwError(function.name, fmt.Sprintf("Class '%s' must be declared abstract to have abstract method '%s'.", name.Lexeme, function.name.Lexeme))
+			}
+
+			methods = append(methods, function)
 		}
 	}
 
 	parser.consume(references.RightBrace, "Expect '}' after class body.")
 
-	if _, ok := declaredClasses[name.Lexeme]; ok {
+	for _, use := range uses {
+		for _, method := range methods {
+			if method.name.Lexeme == use.method.Lexeme {
+				throwError(use.method, fmt.Sprintf("Class '%s' can't both define '%s' and use it from '%s'.", name.Lexeme, use.method.Lexeme, use.trait.Lexeme))
+			}
+		}
+	}
+
+	if _, ok := parser.declaredClasses[name.Lexeme]; ok {
 		throwError(name, fmt.Sprintf("Class '%s' has already been defined.", name.Lexeme))
 	}
 
-	declaredClasses[name.Lexeme] = true
+	parser.declaredClasses[name.Lexeme] = true
+
+	return NewClass(name, superclass, traits, interfaces, methods, fields, uses, isAbstract)
+}
+
+// isUseClause reports whether the class body continues with 'use', which is
+// only a keyword there when followed by a trait name.
+func (parser *AstParser) isUseClause() bool {
+	if !parser.check(references.Identifier) || parser.peek().Lexeme != "use" || parser.Current+1 >= len(parser.Tokens) {
+		return false
+	}
+
+	return parser.Tokens[parser.Current+1].Type == references.Identifier
+}
+
+func (parser *AstParser) traitUse(traits []*Variable) *TraitUse {
+	parser.advance()
+	trait := parser.consume(references.Identifier, "Expect trait name after 'use'.")
+	parser.consume(references.Dot, "Expect '.' after trait name.")
+	method := parser.consume(references.Identifier, "Expect method name after '.'.")
+	parser.consume(references.Semicolon, "Expect ';' after use clause.")
+
+	for _, t := range traits {
+		if t.name.Lexeme == trait.Lexeme {
+			return &TraitUse{trait: trait, method: method}
+		}
+	}
+
+	throwError(trait, fmt.Sprintf("'%s' is not a trait of this class.", trait.Lexeme))
+	return nil
+}
+
+func (parser *AstParser) traitDeclaration() Stmt {
+	name := parser.consume(references.Identifier, "Expect trait name.")
+	parser.consume(references.LeftBrace, "Expect '{' before trait body.")
+
+	var methods []*Function
+	for !parser.check(references.RightBrace) && !parser.isAtEnd() {
+		method := parser.function("method")
+		if method == nil {
+			throwError(parser.peek(), "Traits can't declare fields.")
+		}
+
+		function := method.(*Function)
+		if function.name.Lexeme == "init" {
+			throwError(function.name, "Traits can't declare an initializer.")
+		}
+
+		methods = append(methods, function)
+	}
+
+	parser.consume(references.RightBrace, "Expect '}' after trait body.")
+
+	parser.declaredTypes[name.Lexeme] = true
+	return NewTrait(name, methods)
+}
+
+func (parser *AstParser) interfaceDeclaration() Stmt {
+	name := parser.consume(references.Identifier, "Expect interface name.")
+	parser.consume(references.LeftBrace, "Expect '{' before interface body.")
+
+	var methods []*Function
+	for !parser.check(references.RightBrace) && !parser.isAtEnd() {
+		methodName := parser.consume(references.Identifier, "Expect method name.")
+		params := parser.parameters("method")
+		parser.consume(references.Semicolon, "Expect ';' after interface method.")
+
+		methods = append(methods, NewFunction(methodName, params, nil, false, true).(*Function))
+	}
+
+	parser.consume(references.RightBrace, "Expect '}' after interface body.")
 
-	return NewClass(name, superclass, methods, fields)
+	parser.declaredTypes[name.Lexeme] = true
+	return NewInterfaceCmd(name, methods)
 }
 
 func (parser *AstParser) function(kind string) Stmt {
+	isAbstract := false
+	if kind == "method" && parser.peek().Type == references.Abstract {
+		isAbstract = true
+		parser.consume(references.Abstract, "Expect abstract declaration for abstract method.")
+	}
+
 	isStatic := false
 	if parser.peek().Type == references.Static {
 		isStatic = true
@@ -99,6 +267,28 @@ func (parser *AstParser) function(kind string) Stmt {
 		return nil
 	}
 
+	if isAbstract && isStatic {
+		throwError(name, "Static methods can't be abstract.")
+	}
+
+	params := parser.parameters(kind)
+
+	if isAbstract {
+		parser.consume(references.Semicolon, "Expect ';' after abstract method.")
+		return NewFunction(name, params, nil, false, true)
+	}
+
+	parser.consume(references.LeftBrace, fmt.Sprintf("Expect '{' before %s body.", kind))
+
+	ctx := parser.staticContext
+	parser.staticContext = isStatic
+	body := parser.block()
+	parser.staticContext = ctx
+
+	return NewFunction(name, params, body, isStatic, false)
+}
+
+func (parser *AstParser) parameters(kind string) []*scanner.Token {
 	parser.consume(references.LeftParen, fmt.Sprintf("Expect '(' after %s name", kind))
 
 	var params []*scanner.Token
@@ -113,14 +303,7 @@ func (parser *AstParser) function(kind string) Stmt {
 	}
 
 	parser.consume(references.RightParen, "Expect ')' after parameters.")
-	parser.consume(references.LeftBrace, fmt.Sprintf("Expect '{' before %s body.", kind))
-
-	ctx := staticContext
-	staticContext = isStatic
-	body := parser.block()
-	staticContext = ctx
-
-	return NewFunction(name, params, body, isStatic)
+	return params
 }
 
 func (parser *AstParser) varDeclaration() Stmt {
@@ -136,14 +319,30 @@ func (parser *AstParser) varDeclaration() Stmt {
 }
 
 func (parser *AstParser) statement() Stmt {
+	if parser.check(references.Identifier) && parser.peekNext().Type == references.Colon {
+		return parser.labeledStatement()
+	}
+
 	if parser.match(references.For) {
-		return parser.forStatement()
+		return parser.forStatement(nil)
 	}
 
 	if parser.match(references.If) {
 		return parser.ifStatement()
 	}
 
+	if parser.match(references.Match) {
+		return parser.matchStatement()
+	}
+
+	if parser.match(references.Throw) {
+		return parser.throwStatement()
+	}
+
+	if parser.match(references.Try) {
+		return parser.tryStatement()
+	}
+
 	if parser.match(references.Print) {
 		return parser.printStatement()
 	}
@@ -152,12 +351,20 @@ func (parser *AstParser) statement() Stmt {
 		return parser.returnStatement()
 	}
 
+	if parser.match(references.Defer) {
+		return parser.deferStatement()
+	}
+
 	if parser.match(references.While) {
-		return parser.whileStatement()
+		return parser.whileStatement(nil)
+	}
+
+	if parser.match(references.Do) {
+		return parser.doStatement(nil)
 	}
 
 	if parser.match(references.LeftBrace) {
-		return NewBlock(parser.block(), false)
+		return NewBlock(parser.block())
 	}
 
 	if parser.match(references.Break) {
@@ -171,6 +378,63 @@ func (parser *AstParser) statement() Stmt {
 	return parser.expressionStatement()
 }
 
+func (parser *AstParser) labeledStatement() Stmt {
+	label := parser.advance()
+	parser.consume(references.Colon, "Expect ':' after label.")
+
+	if parser.match(references.For) {
+		return parser.forStatement(label)
+	}
+
+	if parser.match(references.While) {
+		return parser.whileStatement(label)
+	}
+
+	if parser.match(references.Do) {
+		return parser.doStatement(label)
+	}
+
+	throwError(parser.peek(), "Expect a loop after label.")
+	return nil
+}
+
+func (parser *AstParser) throwStatement() Stmt {
+	keyword := parser.previous()
+	value := parser.expression()
+	parser.consume(references.Semicolon, "Expect ';' after thrown value.")
+
+	return NewThrow(keyword, value)
+}
+
+func (parser *AstParser) tryStatement() Stmt {
+	keyword := parser.previous()
+	parser.consume(references.LeftBrace, "Expect '{' after try.")
+	body := parser.block()
+
+	var catchName *scanner.Token
+	var catchBody []Stmt
+	if parser.match(references.Catch) {
+		parser.consume(references.LeftParen, "Expect '(' after catch.")
+		catchName = parser.consume(references.Identifier, "Expect exception variable name.")
+		parser.consume(references.RightParen, "Expect ')' after exception variable.")
+		parser.consume(references.LeftBrace, "Expect '{' before catch body.")
+		catchBody = parser.block()
+	}
+
+	var finallyBody []Stmt
+	hasFinally := parser.match(references.Finally)
+	if hasFinally {
+		parser.consume(references.LeftBrace, "Expect '{' after finally.")
+		finallyBody = parser.block()
+	}
+
+	if catchName == nil && !hasFinally {
+		throwError(keyword, "Expect 'catch' or 'finally' after try block.")
+	}
+
+	return NewTry(keyword, body, catchName, catchBody, hasFinally, finallyBody)
+}
+
 func (parser *AstParser) returnStatement() Stmt {
 	keyword := parser.previous()
 
@@ -183,33 +447,46 @@ func (parser *AstParser) returnStatement() Stmt {
 	return NewReturnCmd(keyword, value)
 }
 
+func (parser *AstParser) deferStatement() Stmt {
+	keyword := parser.previous()
+	expression := parser.expression()
+	parser.consume(references.Semicolon, "Expect ';' after deferred expression.")
+
+	return NewDeferCmd(keyword, expression)
+}
+
 func (parser *AstParser) continueStatement() Stmt {
 	keyword := parser.previous()
-	depth, found := parser.calculateDepth()
 
-	if !found {
-		throwError(parser.previous(), "Expect 'continue' in a loop.")
+	var label *scanner.Token
+	if parser.match(references.Identifier) {
+		label = parser.previous()
 	}
 
 	parser.consume(references.Semicolon, "Expect ';' after continue.")
-	return NewContinueCmd(keyword, depth)
+	return NewContinueCmd(keyword, label)
 }
 
 func (parser *AstParser) breakStatement() Stmt {
 	keyword := parser.previous()
-	depth, found := parser.calculateDepth()
 
-	if !found {
-		throwError(parser.previous(), "Expect 'break' in a loop.")
+	var label *scanner.Token
+	if parser.match(references.Identifier) {
+		label = parser.previous()
 	}
 
 	parser.consume(references.Semicolon, "Expect ';' after break.")
-	return NewBreakCmd(keyword, depth)
+	return NewBreakCmd(keyword, label)
 }
 
-func (parser *AstParser) forStatement() Stmt {
+func (parser *AstParser) forStatement(label *scanner.Token) Stmt {
+	keyword := parser.previous()
 	parser.consume(references.LeftParen, "Expect '(' after for.")
 
+	if parser.check(references.Identifier) && (parser.peekNext().Type == references.In || parser.peekNext().Type == references.Comma) {
+		return parser.forInStatement(keyword, label)
+	}
+
 	var initializer Stmt
 	if parser.match(references.Semicolon) {
 		initializer = nil
@@ -233,30 +510,190 @@ func (parser *AstParser) forStatement() Stmt {
 
 	body := parser.statement()
 
-	if increment != nil {
-		body = NewBlock([]Stmt{body, NewExpression(increment)}, true)
-	}
-
 	if conditional == nil {
 		conditional = NewLiteral(true)
 	}
-	body = NewWhileLoop(conditional, body)
+	body = NewWhileLoop(conditional, body, increment, label)
 
 	if initializer != nil {
-		body = NewBlock([]Stmt{initializer, body}, false)
+		body = NewBlock([]Stmt{initializer, body})
 	}
 
 	return body
 }
 
-func (parser *AstParser) whileStatement() Stmt {
+func (parser *AstParser) forInStatement(keyword *scanner.Token, label *scanner.Token) Stmt {
+	variables := []*scanner.Token{parser.consume(references.Identifier, "Expect variable name.")}
+	if parser.match(references.Comma) {
+		variables = append(variables, parser.consume(references.Identifier, "Expect second variable name."))
+	}
+
+	parser.consume(references.In, "Expect 'in' after for loop variables.")
+	iterable := parser.expression()
+	parser.consume(references.RightParen, "Expect ')' after for loop iterable.")
+
+	body := parser.statement()
+
+	return NewForIn(keyword, variables, iterable, body, label)
+}
+
+func (parser *AstParser) whileStatement(label *scanner.Token) Stmt {
 	parser.consume(references.LeftParen, "Expect '(' after while.")
 	condition := parser.expression()
 	parser.consume(references.RightParen, "Expect ')' after while condition.")
 
 	body := parser.statement()
 
-	return NewWhileLoop(condition, body)
+	return NewWhileLoop(condition, body, nil, label)
+}
+
+// doStatement parses 'do body while (cond);' and 'do body until (cond);'.
+// 'until' is only a keyword in this position.
+func (parser *AstParser) doStatement(label *scanner.Token) Stmt {
+	body := parser.statement()
+
+	until := false
+	var keyword *scanner.Token
+	if parser.match(references.While) {
+		keyword = parser.previous()
+	} else if parser.check(references.Identifier) && parser.peek().Lexeme == "until" {
+		keyword = parser.advance()
+		until = true
+	} else {
+		throwError(parser.peek(), "Expect 'while' or 'until' after do body.")
+	}
+
+	parser.consume(references.LeftParen, fmt.Sprintf("Expect '(' after %s.", keyword.Lexeme))
+	condition := parser.expression()
+	parser.consume(references.RightParen, fmt.Sprintf("Expect ')' after %s condition.", keyword.Lexeme))
+	parser.consume(references.Semicolon, "Expect ';' after do loop.")
+
+	return NewDoWhile(body, keyword, condition, until, label)
+}
+
+func (parser *AstParser) matchStatement() Stmt {
+	keyword := parser.previous()
+	parser.consume(references.LeftParen, "Expect '(' after match.")
+	subject := parser.expression()
+	parser.consume(references.RightParen, "Expect ')' after match value.")
+	parser.consume(references.LeftBrace, "Expect '{' before match cases.")
+
+	var cases []*scanner.Token
+	var patterns [][]Pattern
+	var guards []Expr
+	var bodies []Stmt
+	for !parser.check(references.RightBrace) && !parser.isAtEnd() {
+		cases = append(cases, parser.consume(references.Case, "Expect 'case' in match body."))
+
+		var alternatives []Pattern
+		for ok := true; ok; ok = parser.match(references.Comma) {
+			alternatives = append(alternatives, parser.pattern())
+		}
+
+		var guard Expr
+		if parser.match(references.If) {
+			guard = parser.expression()
+		}
+
+		parser.consume(references.Arrow, "Expect '=>' after case pattern.")
+
+		patterns = append(patterns, alternatives)
+		guards = append(guards, guard)
+		bodies = append(bodies, parser.statement())
+	}
+
+	parser.consume(references.RightBrace, "Expect '}' after match cases.")
+	return NewMatch(keyword, subject, cases, patterns, guards, bodies)
+}
+
+func (parser *AstParser) pattern() Pattern {
+	if parser.match(references.Number, references.Minus) {
+		token := parser.previous()
+		low := parser.number(token)
+
+		if parser.match(references.DotDot, references.DotDotLess) {
+			operator := parser.previous()
+			high := parser.number(parser.advance())
+			return NewMatchRange(operator, low, high)
+		}
+
+		return NewMatchLiteral(token, low)
+	}
+
+	if parser.match(references.String) {
+		return NewMatchLiteral(parser.previous(), parser.previous().Literal)
+	}
+
+	if parser.match(references.True) {
+		return NewMatchLiteral(parser.previous(), true)
+	}
+
+	if parser.match(references.False) {
+		return NewMatchLiteral(parser.previous(), false)
+	}
+
+	if parser.match(references.Nil) {
+		return NewMatchLiteral(parser.previous(), nil)
+	}
+
+	if parser.match(references.LeftBracket) {
+		bracket := parser.previous()
+		elements := parser.patterns(references.RightBracket)
+		parser.consume(references.RightBracket, "Expect ']' after list pattern.")
+		return NewMatchList(bracket, elements)
+	}
+
+	if parser.match(references.LeftParen) {
+		paren := parser.previous()
+		elements := parser.patterns(references.RightParen)
+		parser.consume(references.RightParen, "Expect ')' after tuple pattern.")
+		return NewMatchTuple(paren, elements)
+	}
+
+	name := parser.consume(references.Identifier, "Expect pattern.")
+	if name.Lexeme == "_" {
+		return NewMatchWildcard(name)
+	}
+
+	if parser.match(references.LeftParen) {
+		fields := parser.patterns(references.RightParen)
+		parser.consume(references.RightParen, "Expect ')' after class pattern fields.")
+		return NewMatchClass(NewVariable(name, references.Klass).(*Variable), fields)
+	}
+
+	if parser.declaredClasses[name.Lexeme] || parser.declaredTypes[name.Lexeme] {
+		return NewMatchClass(NewVariable(name, references.Klass).(*Variable), nil)
+	}
+
+	return NewMatchBinding(name)
+}
+
+func (parser *AstParser) patterns(closing references.TokenType) []Pattern {
+	var patterns []Pattern
+	for !parser.check(closing) && !parser.isAtEnd() {
+		patterns = append(patterns, parser.pattern())
+		if !parser.match(references.Comma) {
+			break
+		}
+	}
+
+	return patterns
+}
+
+// number reads a number literal in a pattern, which may be negated.
+func (parser *AstParser) number(token *scanner.Token) float64 {
+	sign := float64(1)
+	if token.Type == references.Minus {
+		sign = -1
+		token = parser.consume(references.Number, "Expect number after '-'.")
+	}
+
+	value, ok := token.Literal.(float64)
+	if !ok {
+		throwError(token, "Expect number in range pattern.")
+	}
+
+	return sign * value
 }
 
 func (parser *AstParser) ifStatement() Stmt {
@@ -317,6 +754,8 @@ func (parser *AstParser) assignment() Expr {
 			return NewSet(val.object, val.name, value)
 		} else if val, ok := expr.(*GetField); ok {
 			return NewSet(val.object, val.name, value)
+		} else if val, ok := expr.(*Index); ok {
+			return NewSetIndex(val.object, val.bracket, val.index, value)
 		}
 
 		throwError(equals, "Invalid assignment target.")
@@ -405,17 +844,40 @@ func (parser *AstParser) equality() Expr {
 }
 
 func (parser *AstParser) comparison() Expr {
-	expr := parser.addition()
+	expr := parser.rangeExpression()
 
-	for parser.match(references.Greater, references.GreaterEqual, references.Less, references.LessEqual) {
+	for parser.match(references.Greater, references.GreaterEqual, references.Less, references.LessEqual, references.Is) {
 		operator := parser.previous()
-		right := parser.addition()
+		right := parser.rangeExpression()
+		if v, ok := right.(*Variable); ok && operator.Type == references.Is {
+			v.t = references.Klass
+		}
+
 		expr = NewBinary(expr, operator, right)
 	}
 
 	return expr
 }
 
+func (parser *AstParser) rangeExpression() Expr {
+	expr := parser.addition()
+
+	if parser.match(references.DotDot, references.DotDotLess) {
+		operator := parser.previous()
+		end := parser.addition()
+
+		var step Expr
+		if parser.check(references.Identifier) && parser.peek().Lexeme == "step" {
+			parser.advance()
+			step = parser.addition()
+		}
+
+		return NewRangeLiteral(expr, operator, end, step)
+	}
+
+	return expr
+}
+
 func (parser *AstParser) addition() Expr {
 	expr := parser.multiplication()
 
@@ -472,23 +934,36 @@ func (parser *AstParser) call() Expr {
 			if isInstance {
 				isInstance = false
 
+				// Classes exported by a module can only be checked at runtime.
+				if get, ok := expr.(*GetMethod); ok {
+					if _, ok := get.object.(*Variable); ok {
+						expr = parser.finishCall(expr, true)
+						continue
+					}
+				}
+
 				if _, ok := expr.(*Variable); !ok {
 					throwError(prev, "Expected class name after 'new'.")
 				}
 
-				if _, ok := declaredClasses[prev.Lexeme]; !ok {
-					throwError(prev, fmt.Sprintf("Undefined class '%s'.", prev.Lexeme))
-				} else {
+				// Variables holding a class, such as the result of classOf(),
+				// are also checked at runtime.
+				if _, ok := parser.declaredClasses[prev.Lexeme]; ok {
 					expr.(*Variable).t = references.Klass
 				}
-			} else {
-				if _, ok := declaredClasses[prev.Lexeme]; ok {
-					throwError(prev, "Expected 'new' before instantiation.")
-				}
+
+				expr = parser.finishCall(expr, true)
+				continue
 			}
-			expr = parser.finishCall(expr)
+
+			if _, ok := parser.declaredClasses[prev.Lexeme]; ok {
+				throwError(prev, "Expected 'new' before instantiation.")
+			}
+			expr = parser.finishCall(expr, false)
+		} else if parser.match(references.LeftBracket) {
+			expr = parser.finishIndex(expr)
 		} else if parser.match(references.Dot) {
-			name := parser.consume(references.Identifier, "Expect property name after '.'.")
+			name := parser.propertyName()
 			if parser.peek().Type == references.LeftParen {
 				expr = NewGetMethod(expr, name)
 			} else {
@@ -502,7 +977,18 @@ func (parser *AstParser) call() Expr {
 	return expr
 }
 
-func (parser *AstParser) finishCall(callee Expr) Expr {
+// propertyName consumes the name after a '.'. The 'match' keyword is also
+// allowed, since it is the name of a Regex method.
+func (parser *AstParser) propertyName() *scanner.Token {
+	if parser.check(references.Identifier) || parser.check(references.Match) {
+		return parser.advance()
+	}
+
+	throwError(parser.peek(), "Expect property name after '.'.")
+	return nil
+}
+
+func (parser *AstParser) finishCall(callee Expr, isNew bool) Expr {
 	var arguments []Expr
 	if !parser.check(references.RightParen) {
 		for ok := true; ok; ok = parser.match(references.Comma) {
@@ -514,7 +1000,29 @@ func (parser *AstParser) finishCall(callee Expr) Expr {
 	}
 
 	paren := parser.consume(references.RightParen, "Expect ')' after arguments.")
-	return NewCall(callee, paren, arguments)
+	return NewCall(callee, paren, arguments, isNew)
+}
+
+func (parser *AstParser) finishIndex(object Expr) Expr {
+	bracket := parser.previous()
+
+	var start Expr
+	if !parser.check(references.Colon) {
+		start = parser.expression()
+	}
+
+	if parser.match(references.Colon) {
+		var end Expr
+		if !parser.check(references.RightBracket) {
+			end = parser.expression()
+		}
+
+		parser.consume(references.RightBracket, "Expect ']' after slice.")
+		return NewSlice(object, bracket, start, end)
+	}
+
+	parser.consume(references.RightBracket, "Expect ']' after index.")
+	return NewIndex(object, bracket, start)
 }
 
 func (parser *AstParser) primary() Expr {
@@ -542,7 +1050,7 @@ func (parser *AstParser) primary() Expr {
 	}
 
 	if parser.match(references.This) {
-		if staticContext {
+		if parser.staticContext {
 			throwError(parser.peek(), "Can't access 'this' in a static context.")
 		}
 
@@ -554,11 +1062,62 @@ func (parser *AstParser) primary() Expr {
 	}
 
 	if parser.match(references.LeftParen) {
+		paren := parser.previous()
+		if parser.match(references.RightParen) {
+			return NewTupleLiteral(paren, nil)
+		}
+
 		expr := parser.expression()
+		if parser.match(references.Comma) {
+			elements := []Expr{expr}
+			for !parser.check(references.RightParen) && !parser.isAtEnd() {
+				elements = append(elements, parser.expression())
+				if !parser.match(references.Comma) {
+					break
+				}
+			}
+
+			parser.consume(references.RightParen, "Expect ')' after tuple elements.")
+			return NewTupleLiteral(paren, elements)
+		}
+
 		parser.consume(references.RightParen, "Expected ')' after expression.")
 		return NewGrouping(expr)
 	}
 
+	if parser.match(references.LeftBracket) {
+		bracket := parser.previous()
+
+		var elements []Expr
+		for !parser.check(references.RightBracket) && !parser.isAtEnd() {
+			elements = append(elements, parser.expression())
+			if !parser.match(references.Comma) {
+				break
+			}
+		}
+
+		parser.consume(references.RightBracket, "Expect ']' after list elements.")
+		return NewListLiteral(bracket, elements)
+	}
+
+	if parser.match(references.LeftBrace) {
+		brace := parser.previous()
+
+		var keys []Expr
+		var values []Expr
+		for !parser.check(references.RightBrace) && !parser.isAtEnd() {
+			keys = append(keys, parser.expression())
+			parser.consume(references.Colon, "Expect ':' after map key.")
+			values = append(values, parser.expression())
+			if !parser.match(references.Comma) {
+				break
+			}
+		}
+
+		parser.consume(references.RightBrace, "Expect '}' after map entries.")
+		return NewMapLiteral(brace, keys, values)
+	}
+
 	throwError(parser.peek(), "Expect expression.")
 	return nil
 }
@@ -583,6 +1142,16 @@ func (parser *AstParser) synchronize() {
 		switch parser.peek().Type {
 		case references.Class:
 			return
+		case references.Import:
+			return
+		case references.Export:
+			return
+		case references.Abstract:
+			return
+		case references.Interface:
+			return
+		case references.Trait:
+			return
 		case references.Fun:
 			return
 		case references.Var:
@@ -591,12 +1160,22 @@ func (parser *AstParser) synchronize() {
 			return
 		case references.If:
 			return
+		case references.Match:
+			return
+		case references.Throw:
+			return
+		case references.Try:
+			return
 		case references.While:
 			return
+		case references.Do:
+			return
 		case references.Print:
 			return
 		case references.Return:
 			return
+		case references.Defer:
+			return
 		}
 
 		parser.advance()
@@ -638,6 +1217,14 @@ func (parser *AstParser) peek() *scanner.Token {
 	return parser.Tokens[parser.Current]
 }
 
+func (parser *AstParser) peekNext() *scanner.Token {
+	if parser.isAtEnd() {
+		return parser.peek()
+	}
+
+	return parser.Tokens[parser.Current+1]
+}
+
 func (parser *AstParser) previous() *scanner.Token {
 	return parser.Tokens[parser.Current-1]
 }
@@ -654,70 +1241,21 @@ func (parser *AstParser) previousIndex(index int) *scanner.Token {
 	return parser.Tokens[index]
 }
 
-func (parser *AstParser) calculateDepth() (int, bool) {
-	curr := parser.Current - 1
-	depth := 0
-	leftBraces := 0
-	rightBraces := 0
-	prev := parser.previousIndex(curr)
-	found := false
-	for prev != nil {
-		if prev.Type == references.RightBrace {
-			rightBraces++
-		}
-
-		if prev.Type == references.LeftBrace {
-			leftBraces++
-			if leftBraces > rightBraces {
-				depth++
-			}
-		}
-
-		if leftBraces > rightBraces && (prev.Type == references.For || prev.Type == references.While) {
-			// if type is for loop then look for the initializer and increment the depth
-			if prev.Type == references.For {
-				forCurr := curr + 1
-				forPrev := parser.previousIndex(forCurr)
-				forFound := false
-				for forPrev != nil && forPrev.Type != references.Semicolon && forPrev.Type != references.RightParen {
-					if forPrev.Type == references.Var {
-						forFound = true
-						break
-					}
-
-					forCurr++
-					forPrev = parser.previousIndex(forCurr)
-				}
-
-				// increment depth because an initializer means a second enclosing block
-				if forFound {
-					depth++
-				}
-			}
-
-			found = true
-			break
-		}
-
-		curr--
-		prev = parser.previousIndex(curr)
-	}
-
-	return depth, found
-}
-
 func throwError(token *scanner.Token, message string) {
 	loxerror.TokenError(token.Type, token.Line, token.Lexeme, message)
 
 	panic(fmt.Errorf(message))
 }
 
+// throwRuntimeError raises a RuntimeError. It is only reported if it reaches
+// Interpret without being caught by a try statement.
 func throwRuntimeError(token *scanner.Token, message string) {
-	loxerror.TokenRuntimeError(token.Type, token.Line, token.Lexeme, message, true)
-
-	panic(fmt.Errorf(message))
+	panic(&RuntimeError{
+		token:   token,
+		message: message,
+	})
 }
 
 func throwReturn(obj interface{}) {
-	panic(obj)
+	panic(&returnValue{value: obj})
 }
@@ -0,0 +1,156 @@
+package syntax
+
+import "golox/scanner"
+
+type Pattern interface{
+	accept(visitor PatternVisitor) interface{}
+	String() string}
+
+type PatternVisitor interface {
+	visitMatchLiteralPattern(pattern *MatchLiteral) interface{}
+	visitMatchRangePattern(pattern *MatchRange) interface{}
+	visitMatchWildcardPattern(pattern *MatchWildcard) interface{}
+	visitMatchBindingPattern(pattern *MatchBinding) interface{}
+	visitMatchClassPattern(pattern *MatchClass) interface{}
+	visitMatchListPattern(pattern *MatchList) interface{}
+	visitMatchTuplePattern(pattern *MatchTuple) interface{}
+}
+
+type MatchLiteral struct {
+	token *scanner.Token
+	value interface{}
+}
+
+func NewMatchLiteral(token *scanner.Token, value interface{}) Pattern {
+	return &MatchLiteral{
+		token: token,
+		value: value,
+	}
+}
+
+func (matchliteral *MatchLiteral) accept(visitor PatternVisitor) interface{} {
+	return visitor.visitMatchLiteralPattern(matchliteral)
+}
+
+func (matchliteral *MatchLiteral) String() string {
+	return "MatchLiteral"}
+
+
+type MatchRange struct {
+	operator *scanner.Token
+	low float64
+	high float64
+}
+
+func NewMatchRange(operator *scanner.Token, low float64, high float64) Pattern {
+	return &MatchRange{
+		operator: operator,
+		low: low,
+		high: high,
+	}
+}
+
+func (matchrange *MatchRange) accept(visitor PatternVisitor) interface{} {
+	return visitor.visitMatchRangePattern(matchrange)
+}
+
+func (matchrange *MatchRange) String() string {
+	return "MatchRange"}
+
+
+type MatchWildcard struct {
+	token *scanner.Token
+}
+
+func NewMatchWildcard(token *scanner.Token) Pattern {
+	return &MatchWildcard{
+		token: token,
+	}
+}
+
+func (matchwildcard *MatchWildcard) accept(visitor PatternVisitor) interface{} {
+	return visitor.visitMatchWildcardPattern(matchwildcard)
+}
+
+func (matchwildcard *MatchWildcard) String() string {
+	return "MatchWildcard"}
+
+
+type MatchBinding struct {
+	name *scanner.Token
+}
+
+func NewMatchBinding(name *scanner.Token) Pattern {
+	return &MatchBinding{
+		name: name,
+	}
+}
+
+func (matchbinding *MatchBinding) accept(visitor PatternVisitor) interface{} {
+	return visitor.visitMatchBindingPattern(matchbinding)
+}
+
+func (matchbinding *MatchBinding) String() string {
+	return "MatchBinding"}
+
+
+type MatchClass struct {
+	class *Variable
+	fields []Pattern
+}
+
+func NewMatchClass(class *Variable, fields []Pattern) Pattern {
+	return &MatchClass{
+		class: class,
+		fields: fields,
+	}
+}
+
+func (matchclass *MatchClass) accept(visitor PatternVisitor) interface{} {
+	return visitor.visitMatchClassPattern(matchclass)
+}
+
+func (matchclass *MatchClass) String() string {
+	return "MatchClass"}
+
+
+type MatchList struct {
+	bracket *scanner.Token
+	elements []Pattern
+}
+
+func NewMatchList(bracket *scanner.Token, elements []Pattern) Pattern {
+	return &MatchList{
+		bracket: bracket,
+		elements: elements,
+	}
+}
+
+func (matchlist *MatchList) accept(visitor PatternVisitor) interface{} {
+	return visitor.visitMatchListPattern(matchlist)
+}
+
+func (matchlist *MatchList) String() string {
+	return "MatchList"}
+
+
+type MatchTuple struct {
+	paren *scanner.Token
+	elements []Pattern
+}
+
+func NewMatchTuple(paren *scanner.Token, elements []Pattern) Pattern {
+	return &MatchTuple{
+		paren: paren,
+		elements: elements,
+	}
+}
+
+func (matchtuple *MatchTuple) accept(visitor PatternVisitor) interface{} {
+	return visitor.visitMatchTuplePattern(matchtuple)
+}
+
+func (matchtuple *MatchTuple) String() string {
+	return "MatchTuple"}
+
+
@@ -0,0 +1,128 @@
+package syntax
+
+import (
+	"fmt"
+	"golox/references"
+)
+
+// patternMatcher tests a single value against a pattern, defining any
+// bindings into env as it goes.
+type patternMatcher struct {
+	interpreter *Interpreter
+	value       interface{}
+	env         *Environment
+}
+
+func (interpreter *Interpreter) matches(pattern Pattern, value interface{}, env *Environment) bool {
+	matcher := &patternMatcher{
+		interpreter: interpreter,
+		value:       value,
+		env:         env,
+	}
+
+	return pattern.accept(matcher).(bool)
+}
+
+func (matcher *patternMatcher) visitMatchLiteralPattern(pattern *MatchLiteral) interface{} {
+	return matcher.interpreter.isEqual(matcher.value, pattern.value)
+}
+
+func (matcher *patternMatcher) visitMatchRangePattern(pattern *MatchRange) interface{} {
+	n, ok := matcher.value.(float64)
+	if !ok || n < pattern.low {
+		return false
+	}
+
+	if pattern.operator.Type == references.DotDotLess {
+		return n < pattern.high
+	}
+
+	return n <= pattern.high
+}
+
+func (matcher *patternMatcher) visitMatchWildcardPattern(pattern *MatchWildcard) interface{} {
+	return true
+}
+
+func (matcher *patternMatcher) visitMatchBindingPattern(pattern *MatchBinding) interface{} {
+	matcher.env.define(pattern.name.Lexeme, matcher.value)
+	return true
+}
+
+func (matcher *patternMatcher) visitMatchClassPattern(pattern *MatchClass) interface{} {
+	target := matcher.interpreter.evaluateIn(pattern.class, matcher.env)
+
+	instance, ok := matcher.value.(*LoxInstance)
+	if !ok {
+		return false
+	}
+
+	switch target.(type) {
+	case *LoxClass, *LoxTrait, *LoxInterface:
+	default:
+		throwRuntimeError(pattern.class.name, fmt.Sprintf("'%s' is not a class, trait or interface.", pattern.class.name.Lexeme))
+	}
+
+	if !instance.class.isSubtypeOf(target) {
+		return false
+	}
+
+	if len(pattern.fields) == 0 {
+		return true
+	}
+
+	class, ok := target.(*LoxClass)
+	if !ok {
+		throwRuntimeError(pattern.class.name, fmt.Sprintf("Can't destructure '%s' because it is not a class.", pattern.class.name.Lexeme))
+	}
+
+	names := class.positionalFields()
+	if len(pattern.fields) > len(names) {
+		throwRuntimeError(pattern.class.name, fmt.Sprintf("Class '%s' has %d fields but the pattern has %d.", class.className, len(names), len(pattern.fields)))
+	}
+
+	for i, field := range pattern.fields {
+		value, ok := instance.fields[names[i]]
+		if !ok {
+			throwRuntimeError(pattern.class.name, fmt.Sprintf("Can't destructure '%s' because the instance has no field '%s'.", class.className, names[i]))
+		}
+
+		if !matcher.interpreter.matches(field, value, matcher.env) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (matcher *patternMatcher) visitMatchListPattern(pattern *MatchList) interface{} {
+	list, ok := matcher.value.(*LoxList)
+	if !ok {
+		return false
+	}
+
+	return matcher.elements(pattern.elements, list.elements)
+}
+
+func (matcher *patternMatcher) visitMatchTuplePattern(pattern *MatchTuple) interface{} {
+	tuple, ok := matcher.value.(*LoxTuple)
+	if !ok {
+		return false
+	}
+
+	return matcher.elements(pattern.elements, tuple.elements)
+}
+
+func (matcher *patternMatcher) elements(patterns []Pattern, values []interface{}) bool {
+	if len(patterns) != len(values) {
+		return false
+	}
+
+	for i, pattern := range patterns {
+		if !matcher.interpreter.matches(pattern, values[i], matcher.env) {
+			return false
+		}
+	}
+
+	return true
+}
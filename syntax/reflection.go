@@ -0,0 +1,160 @@
+package syntax
+
+import "sort"
+
+func defineReflection(env *Environment) {
+	env.define("typeof", NewNativeFunction("typeof", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return typeOf(arguments[0])
+	}))
+
+	env.define("classOf", NewNativeFunction("classOf", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return interpreter.instanceArgument("classOf", arguments, 0).class
+	}))
+
+	env.define("fields", NewNativeFunction("fields", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		instance := interpreter.instanceArgument("fields", arguments, 0)
+
+		var names []string
+		for name := range instance.fields {
+			names = append(names, name)
+		}
+
+		return sortedNames(names)
+	}))
+
+	env.define("methods", NewNativeFunction("methods", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		class := interpreter.classArgument("methods", arguments, 0)
+
+		var names []string
+		seen := make(map[string]bool)
+		for c := class; c != nil; c = c.superclass {
+			for name, method := range c.methods {
+				if seen[name] || (c != class && method.isStatic) {
+					continue
+				}
+
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+
+		return sortedNames(names)
+	}))
+
+	env.define("superclass", NewNativeFunction("superclass", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		if superclass := interpreter.classArgument("superclass", arguments, 0).superclass; superclass != nil {
+			return superclass
+		}
+
+		return nil
+	}))
+
+	env.define("hasField", NewNativeFunction("hasField", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		instance, ok := arguments[0].(*LoxInstance)
+		if !ok {
+			return false
+		}
+
+		_, ok = instance.fields[interpreter.stringArgument("hasField", arguments, 1)]
+		return ok
+	}))
+
+	env.define("getField", NewNativeFunction("getField", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		instance := interpreter.instanceArgument("getField", arguments, 0)
+		name := interpreter.stringArgument("getField", arguments, 1)
+
+		value, ok := instance.fields[name]
+		if !ok {
+			interpreter.nativeError("Undefined field '%s'.", name)
+		}
+
+		return value
+	}))
+
+	env.define("setField", NewNativeFunction("setField", 3, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		instance := interpreter.instanceArgument("setField", arguments, 0)
+		instance.fields[interpreter.stringArgument("setField", arguments, 1)] = arguments[2]
+		return arguments[2]
+	}))
+
+	env.define("arity", NewNativeFunction("arity", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+		return float64(interpreter.callableArgument("arity", arguments, 0).arity())
+	}))
+}
+
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "bool"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case *LoxList:
+		return "list"
+	case *LoxMap:
+		return "map"
+	case *LoxTuple:
+		return "tuple"
+	case *LoxSet:
+		return "set"
+	case *LoxRange:
+		return "range"
+	case *LoxInstance:
+		return "instance"
+	case *LoxClass:
+		return "class"
+	case *LoxTrait:
+		return "trait"
+	case *LoxInterface:
+		return "interface"
+	case *LoxModule:
+		return "module"
+	case *LoxIterator:
+		return "iterator"
+	case *LoxDateTime:
+		return "datetime"
+	case *LoxRegex:
+		return "regex"
+	case LoxCallable:
+		return "function"
+	}
+
+	return "unknown"
+}
+
+func sortedNames(names []string) *LoxList {
+	sort.Strings(names)
+
+	elements := make([]interface{}, len(names))
+	for i, name := range names {
+		elements[i] = name
+	}
+
+	return NewLoxList(elements)
+}
+
+func (interpreter *Interpreter) instanceArgument(native string, arguments []interface{}, index int) *LoxInstance {
+	value, ok := arguments[index].(*LoxInstance)
+	if !ok {
+		interpreter.nativeError("Argument %d of '%s' must be an instance.", index+1, native)
+	}
+
+	return value
+}
+
+// classArgument accepts either a class or an instance of one.
+func (interpreter *Interpreter) classArgument(native string, arguments []interface{}, index int) *LoxClass {
+	if instance, ok := arguments[index].(*LoxInstance); ok {
+		return instance.class
+	}
+
+	value, ok := arguments[index].(*LoxClass)
+	if !ok {
+		interpreter.nativeError("Argument %d of '%s' must be a class.", index+1, native)
+	}
+
+	return value
+}
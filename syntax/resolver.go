@@ -12,12 +12,14 @@ var currentClass references.ClassType = references.NoneClass
 type VariableData struct {
 	variableType references.FunctionType
 	defined      bool
+	hoisted      bool
 }
 
 type Resolver struct {
 	interpreter     *Interpreter
 	scopes          *Stack
 	currentFunction references.FunctionType
+	loops           []Stmt
 }
 
 func NewResolver(interpreter *Interpreter) *Resolver {
@@ -42,8 +44,46 @@ func (resolver *Resolver) Resolve(stmts []Stmt) {
 	}()
 
 	resolver.beginScope()
+	resolver.declareGlobals()
+	resolver.beginScope()
+	resolver.hoistFunctions(stmts)
 	resolver.resolveStatements(stmts)
 	resolver.endScope()
+	resolver.endScope()
+}
+
+// declareGlobals fills the outermost scope with the names already defined
+// in the interpreter's environment, such as natives and the variables of
+// earlier REPL lines. They are looked up dynamically rather than by depth.
+func (resolver *Resolver) declareGlobals() {
+	scope := resolver.scopes.Peek().(map[string]*VariableData)
+	for env := resolver.interpreter.env; env != nil; env = env.enclosing {
+		for name, value := range env.values {
+			t := references.None
+			switch value.(type) {
+			case *LoxClass, *LoxTrait, *LoxInterface:
+				t = references.Klass
+			}
+
+			scope[buildKey(name, t)] = &VariableData{variableType: t, defined: true}
+		}
+	}
+}
+
+// hoistFunctions declares the top-level functions up front so that a
+// function can call one declared after it.
+func (resolver *Resolver) hoistFunctions(stmts []Stmt) {
+	for _, stmt := range stmts {
+		if export, ok := stmt.(*Export); ok {
+			stmt = export.declaration
+		}
+
+		if function, ok := stmt.(*Function); ok {
+			resolver.declare(function.name, references.Function)
+			resolver.define(function.name, references.Function)
+			resolver.scopes.Peek().(map[string]*VariableData)[buildKey(function.name.Lexeme, references.Function)].hoisted = true
+		}
+	}
 }
 
 func (resolver *Resolver) visitBlockStmt(stmt *Block) interface{} {
@@ -64,7 +104,7 @@ func (resolver *Resolver) visitVarCmdStmt(stmt *VarCmd) interface{} {
 }
 
 func (resolver *Resolver) visitVariableExpr(expr *Variable) interface{} {
-	if !resolver.scopes.IsEmpty() && !resolver.isDefined(expr.name.Lexeme, expr.t) {
+	if !resolver.scopes.IsEmpty() && resolver.inInitializer(expr.name.Lexeme, expr.t) {
 		throwError(expr.name, fmt.Sprintf("Can't read local variable '%s' in its own initializer.", expr.name.Lexeme))
 	}
 
@@ -81,11 +121,13 @@ func (resolver *Resolver) visitThisExpr(expr *This) interface{} {
 	return nil
 }
 
-func (resolver *Resolver) isDefined(lexeme string, t references.FunctionType) bool {
+// inInitializer reports whether the nearest declaration of lexeme hasn't
+// been defined yet. Names that aren't declared at all are reported by
+// resolveLocal.
+func (resolver *Resolver) inInitializer(lexeme string, t references.FunctionType) bool {
 	for i := resolver.scopes.length - 1; i >= 0; i-- {
-		data, ok := resolver.scopes.Get(i).(map[string]*VariableData)[buildKey(lexeme, t)]
-		if ok {
-			return data.defined
+		if data, ok := lookup(resolver.scopes.Get(i).(map[string]*VariableData), lexeme, t); ok {
+			return !data.defined
 		}
 	}
 
@@ -148,6 +190,14 @@ func (resolver *Resolver) visitClassStmt(stmt *Class) interface{} {
 		resolver.resolveExpression(stmt.superclass)
 	}
 
+	for _, trait := range stmt.traits {
+		resolver.resolveExpression(trait)
+	}
+
+	for _, iface := range stmt.interfaces {
+		resolver.resolveExpression(iface)
+	}
+
 	resolver.beginScope()
 	resolver.scopes.Peek().(map[string]*VariableData)[buildKey("this", references.None)] = &VariableData{
 		variableType: references.Property,
@@ -173,9 +223,40 @@ func (resolver *Resolver) visitClassStmt(stmt *Class) interface{} {
 	return nil
 }
 
+func (resolver *Resolver) visitTraitStmt(stmt *Trait) interface{} {
+	enclosingClassType := currentClass
+	currentClass = references.TraitClass
+
+	resolver.declare(stmt.name, references.Klass)
+	resolver.define(stmt.name, references.Klass)
+
+	resolver.beginScope()
+	resolver.scopes.Peek().(map[string]*VariableData)[buildKey("this", references.None)] = &VariableData{
+		variableType: references.Property,
+		defined:      true,
+	}
+
+	for _, method := range stmt.methods {
+		resolver.resolveFunction(method, references.Method)
+	}
+
+	resolver.endScope()
+
+	currentClass = enclosingClassType
+	return nil
+}
+
+func (resolver *Resolver) visitInterfaceCmdStmt(stmt *InterfaceCmd) interface{} {
+	resolver.declare(stmt.name, references.Klass)
+	resolver.define(stmt.name, references.Klass)
+	return nil
+}
+
 func (resolver *Resolver) visitSuperExpr(expr *Super) interface{} {
 	if currentClass == references.NoneClass {
 		throwError(expr.keyword, "Can't use 'super' outside of a class.")
+	} else if currentClass == references.TraitClass {
+		throwError(expr.keyword, "Can't use 'super' in a trait.")
 	} else if currentClass != references.SubClass {
 		throwError(expr.keyword, "Can't use 'super' in a class with no superclass.")
 	}
@@ -194,24 +275,185 @@ func (resolver *Resolver) visitGetFieldExpr(expr *GetField) interface{} {
 	return nil
 }
 
+func (resolver *Resolver) visitListLiteralExpr(expr *ListLiteral) interface{} {
+	for _, element := range expr.elements {
+		resolver.resolveExpression(element)
+	}
+
+	return nil
+}
+
+func (resolver *Resolver) visitMapLiteralExpr(expr *MapLiteral) interface{} {
+	for i, key := range expr.keys {
+		resolver.resolveExpression(key)
+		resolver.resolveExpression(expr.values[i])
+	}
+
+	return nil
+}
+
+func (resolver *Resolver) visitTupleLiteralExpr(expr *TupleLiteral) interface{} {
+	for _, element := range expr.elements {
+		resolver.resolveExpression(element)
+	}
+
+	return nil
+}
+
+func (resolver *Resolver) visitRangeLiteralExpr(expr *RangeLiteral) interface{} {
+	resolver.resolveExpression(expr.start)
+	resolver.resolveExpression(expr.end)
+	if expr.step != nil {
+		resolver.resolveExpression(expr.step)
+	}
+
+	return nil
+}
+
+func (resolver *Resolver) visitIndexExpr(expr *Index) interface{} {
+	resolver.resolveExpression(expr.object)
+	resolver.resolveExpression(expr.index)
+	return nil
+}
+
+func (resolver *Resolver) visitSetIndexExpr(expr *SetIndex) interface{} {
+	resolver.resolveExpression(expr.value)
+	resolver.resolveExpression(expr.object)
+	resolver.resolveExpression(expr.index)
+	return nil
+}
+
+func (resolver *Resolver) visitSliceExpr(expr *Slice) interface{} {
+	resolver.resolveExpression(expr.object)
+	if expr.start != nil {
+		resolver.resolveExpression(expr.start)
+	}
+
+	if expr.end != nil {
+		resolver.resolveExpression(expr.end)
+	}
+
+	return nil
+}
+
 func (resolver *Resolver) visitPrintStmt(stmt *Print) interface{} {
 	resolver.resolveExpression(stmt.expression)
 	return nil
 }
 
 func (resolver *Resolver) visitBreakCmdStmt(stmt *BreakCmd) interface{} {
-	if resolver.currentFunction == references.None {
-		throwError(stmt.keyword, "Can't break from top-level code.")
+	resolver.resolveJump(stmt, stmt.keyword, stmt.label)
+	return nil
+}
+
+func (resolver *Resolver) visitContinueCmdStmt(stmt *ContinueCmd) interface{} {
+	resolver.resolveJump(stmt, stmt.keyword, stmt.label)
+	return nil
+}
+
+// resolveJump records which loop a break or continue statement leaves: the
+// innermost one, or the enclosing one with a matching label.
+func (resolver *Resolver) resolveJump(stmt Stmt, keyword *scanner.Token, label *scanner.Token) {
+	if len(resolver.loops) == 0 {
+		throwError(keyword, fmt.Sprintf("Can't use '%s' outside of a loop.", keyword.Lexeme))
+	}
+
+	if label == nil {
+		resolver.interpreter.resolveLoop(stmt, resolver.loops[len(resolver.loops)-1])
+		return
+	}
+
+	for i := len(resolver.loops) - 1; i >= 0; i-- {
+		if l := loopLabel(resolver.loops[i]); l != nil && l.Lexeme == label.Lexeme {
+			resolver.interpreter.resolveLoop(stmt, resolver.loops[i])
+			return
+		}
+	}
+
+	throwError(label, fmt.Sprintf("No enclosing loop labeled '%s'.", label.Lexeme))
+}
+
+func (resolver *Resolver) beginLoop(loop Stmt) {
+	if label := loopLabel(loop); label != nil {
+		for _, enclosing := range resolver.loops {
+			if l := loopLabel(enclosing); l != nil && l.Lexeme == label.Lexeme {
+				throwError(label, fmt.Sprintf("Label '%s' is already used by an enclosing loop.", label.Lexeme))
+			}
+		}
+	}
+
+	resolver.loops = append(resolver.loops, loop)
+}
+
+func (resolver *Resolver) endLoop() {
+	resolver.loops = resolver.loops[:len(resolver.loops)-1]
+}
+
+func loopLabel(loop Stmt) *scanner.Token {
+	switch val := loop.(type) {
+	case *WhileLoop:
+		return val.label
+	case *DoWhile:
+		return val.label
+	case *ForIn:
+		return val.label
 	}
 
 	return nil
 }
 
-func (resolver *Resolver) visitContinueCmdStmt(stmt *ContinueCmd) interface{} {
+func (resolver *Resolver) visitThrowStmt(stmt *Throw) interface{} {
+	resolver.resolveExpression(stmt.value)
+	return nil
+}
+
+func (resolver *Resolver) visitTryStmt(stmt *Try) interface{} {
+	resolver.beginScope()
+	resolver.resolveStatements(stmt.body)
+	resolver.endScope()
+
+	if stmt.catchName != nil {
+		resolver.beginScope()
+		resolver.declare(stmt.catchName, references.None)
+		resolver.define(stmt.catchName, references.None)
+		resolver.resolveStatements(stmt.catchBody)
+		resolver.endScope()
+	}
+
+	if stmt.hasFinally {
+		resolver.beginScope()
+		resolver.resolveStatements(stmt.finallyBody)
+		resolver.endScope()
+	}
+
+	return nil
+}
+
+func (resolver *Resolver) visitImportCmdStmt(stmt *ImportCmd) interface{} {
+	if resolver.scopes.Len() > 2 {
+		throwError(stmt.keyword, "Can only import at the top level.")
+	}
+
+	resolver.declare(stmt.name, references.None)
+	resolver.define(stmt.name, references.None)
+	return nil
+}
+
+func (resolver *Resolver) visitExportStmt(stmt *Export) interface{} {
+	if resolver.scopes.Len() > 2 {
+		throwError(stmt.keyword, "Can only export top-level declarations.")
+	}
+
+	resolver.resolveStatement(stmt.declaration)
+	return nil
+}
+
+func (resolver *Resolver) visitDeferCmdStmt(stmt *DeferCmd) interface{} {
 	if resolver.currentFunction == references.None {
-		throwError(stmt.keyword, "Can't continue from top-level code.")
+		throwError(stmt.keyword, "Can't use 'defer' outside of a function.")
 	}
 
+	resolver.resolveExpression(stmt.expression)
 	return nil
 }
 
@@ -233,7 +475,130 @@ func (resolver *Resolver) visitReturnCmdStmt(stmt *ReturnCmd) interface{} {
 
 func (resolver *Resolver) visitWhileLoopStmt(stmt *WhileLoop) interface{} {
 	resolver.resolveExpression(stmt.condition)
+
+	resolver.beginLoop(stmt)
+	resolver.resolveStatement(stmt.body)
+	resolver.endLoop()
+
+	if stmt.increment != nil {
+		resolver.resolveExpression(stmt.increment)
+	}
+
+	return nil
+}
+
+func (resolver *Resolver) visitDoWhileStmt(stmt *DoWhile) interface{} {
+	resolver.beginLoop(stmt)
+	resolver.resolveStatement(stmt.body)
+	resolver.endLoop()
+
+	resolver.resolveExpression(stmt.condition)
+	return nil
+}
+
+func (resolver *Resolver) visitForInStmt(stmt *ForIn) interface{} {
+	resolver.resolveExpression(stmt.iterable)
+
+	resolver.beginScope()
+	for _, variable := range stmt.variables {
+		resolver.declare(variable, references.None)
+		resolver.define(variable, references.None)
+	}
+
+	resolver.beginLoop(stmt)
 	resolver.resolveStatement(stmt.body)
+	resolver.endLoop()
+	resolver.endScope()
+	return nil
+}
+
+func (resolver *Resolver) visitMatchStmt(stmt *Match) interface{} {
+	resolver.resolveExpression(stmt.subject)
+
+	exhausted := false
+	literals := make(map[interface{}]bool)
+	for i, alternatives := range stmt.patterns {
+		if exhausted {
+			throwError(stmt.cases[i], "Unreachable case.")
+		}
+
+		resolver.beginScope()
+		for _, pattern := range alternatives {
+			pattern.accept(resolver)
+
+			if stmt.guards[i] != nil {
+				continue
+			}
+
+			switch p := pattern.(type) {
+			case *MatchWildcard, *MatchBinding:
+				exhausted = true
+			case *MatchLiteral:
+				if literals[p.value] {
+					throwError(p.token, "Unreachable case.")
+				}
+				literals[p.value] = true
+			}
+		}
+
+		if len(alternatives) > 1 && len(resolver.scopes.Peek().(map[string]*VariableData)) > 0 {
+			throwError(stmt.cases[i], "Alternative patterns can't bind variables.")
+		}
+
+		if stmt.guards[i] != nil {
+			resolver.resolveExpression(stmt.guards[i])
+		}
+		resolver.resolveStatement(stmt.bodies[i])
+		resolver.endScope()
+	}
+
+	return nil
+}
+
+func (resolver *Resolver) visitMatchLiteralPattern(pattern *MatchLiteral) interface{} {
+	return nil
+}
+
+func (resolver *Resolver) visitMatchRangePattern(pattern *MatchRange) interface{} {
+	if pattern.low > pattern.high {
+		throwError(pattern.operator, "Range pattern bounds are reversed.")
+	}
+
+	return nil
+}
+
+func (resolver *Resolver) visitMatchWildcardPattern(pattern *MatchWildcard) interface{} {
+	return nil
+}
+
+func (resolver *Resolver) visitMatchBindingPattern(pattern *MatchBinding) interface{} {
+	resolver.declare(pattern.name, references.None)
+	resolver.define(pattern.name, references.None)
+	return nil
+}
+
+func (resolver *Resolver) visitMatchClassPattern(pattern *MatchClass) interface{} {
+	resolver.resolveExpression(pattern.class)
+	for _, field := range pattern.fields {
+		field.accept(resolver)
+	}
+
+	return nil
+}
+
+func (resolver *Resolver) visitMatchListPattern(pattern *MatchList) interface{} {
+	for _, element := range pattern.elements {
+		element.accept(resolver)
+	}
+
+	return nil
+}
+
+func (resolver *Resolver) visitMatchTuplePattern(pattern *MatchTuple) interface{} {
+	for _, element := range pattern.elements {
+		element.accept(resolver)
+	}
+
 	return nil
 }
 
@@ -277,6 +642,9 @@ func (resolver *Resolver) resolveFunction(stmt *Function, functionType reference
 	enclosingFunction := resolver.currentFunction
 	resolver.currentFunction = functionType
 
+	enclosingLoops := resolver.loops
+	resolver.loops = nil
+
 	resolver.beginScope()
 	for _, token := range stmt.params {
 		resolver.declare(token, references.None)
@@ -286,6 +654,7 @@ func (resolver *Resolver) resolveFunction(stmt *Function, functionType reference
 	resolver.resolveStatements(stmt.body)
 	resolver.endScope()
 	resolver.currentFunction = enclosingFunction
+	resolver.loops = enclosingLoops
 }
 
 func (resolver *Resolver) resolveLocal(expr Expr, name *scanner.Token) {
@@ -295,7 +664,11 @@ func (resolver *Resolver) resolveLocal(expr Expr, name *scanner.Token) {
 	}
 
 	for i := resolver.scopes.Len() - 1; i >= 0; i-- {
-		if _, ok := resolver.scopes.Get(i).(map[string]*VariableData)[buildKey(name.Lexeme, t)]; ok {
+		if _, ok := lookup(resolver.scopes.Get(i).(map[string]*VariableData), name.Lexeme, t); ok {
+			if i == 0 {
+				return
+			}
+
 			index := resolver.scopes.Len() - 1 - i
 			resolver.interpreter.resolve(expr, &index)
 			return
@@ -325,7 +698,7 @@ func (resolver *Resolver) declare(name *scanner.Token, t references.FunctionType
 	}
 
 	scope := resolver.scopes.Peek().(map[string]*VariableData)
-	if v, ok := scope[buildKey(name.Lexeme, t)]; ok {
+	if v, ok := scope[buildKey(name.Lexeme, t)]; ok && !v.hoisted {
 		throwError(name, fmt.Sprintf("%s already exists with name %s", references.GetFunctionTypeName(v.variableType), name.Lexeme))
 	}
 
@@ -351,6 +724,21 @@ func (resolver *Resolver) endScope() {
 	resolver.scopes.Pop()
 }
 
+// lookup finds name in scope. A plain variable reference can refer to a
+// variable, function or class of the same name.
+func lookup(scope map[string]*VariableData, name string, t references.FunctionType) (*VariableData, bool) {
+	if data, ok := scope[buildKey(name, t)]; ok || t != references.None {
+		return data, ok
+	}
+
+	if data, ok := scope[buildKey(name, references.Function)]; ok {
+		return data, ok
+	}
+
+	data, ok := scope[buildKey(name, references.Klass)]
+	return data, ok
+}
+
 func buildKey(name string, t references.FunctionType) string {
 	return fmt.Sprintf("%s - %s", name, references.GetFunctionTypeName(t))
 }
@@ -15,20 +15,28 @@ type StmtVisitor interface {
 	visitReturnCmdStmt(stmt *ReturnCmd) interface{}
 	visitVarCmdStmt(stmt *VarCmd) interface{}
 	visitWhileLoopStmt(stmt *WhileLoop) interface{}
+	visitDoWhileStmt(stmt *DoWhile) interface{}
+	visitForInStmt(stmt *ForIn) interface{}
 	visitBreakCmdStmt(stmt *BreakCmd) interface{}
 	visitContinueCmdStmt(stmt *ContinueCmd) interface{}
 	visitClassStmt(stmt *Class) interface{}
+	visitInterfaceCmdStmt(stmt *InterfaceCmd) interface{}
+	visitMatchStmt(stmt *Match) interface{}
+	visitImportCmdStmt(stmt *ImportCmd) interface{}
+	visitExportStmt(stmt *Export) interface{}
+	visitDeferCmdStmt(stmt *DeferCmd) interface{}
+	visitThrowStmt(stmt *Throw) interface{}
+	visitTryStmt(stmt *Try) interface{}
+	visitTraitStmt(stmt *Trait) interface{}
 }
 
 type Block struct {
 	statements []Stmt
-	isLoopIncrementer bool
 }
 
-func NewBlock(statements []Stmt, isLoopIncrementer bool) Stmt {
+func NewBlock(statements []Stmt) Stmt {
 	return &Block{
 		statements: statements,
-		isLoopIncrementer: isLoopIncrementer,
 	}
 }
 
@@ -63,14 +71,16 @@ type Function struct {
 	params []*scanner.Token
 	body []Stmt
 	isStatic bool
+	isAbstract bool
 }
 
-func NewFunction(name *scanner.Token, params []*scanner.Token, body []Stmt, isStatic bool) Stmt {
+func NewFunction(name *scanner.Token, params []*scanner.Token, body []Stmt, isStatic bool, isAbstract bool) Stmt {
 	return &Function{
 		name: name,
 		params: params,
 		body: body,
 		isStatic: isStatic,
+		isAbstract: isAbstract,
 	}
 }
 
@@ -165,12 +175,16 @@ func (varcmd *VarCmd) String() string {
 type WhileLoop struct {
 	condition Expr
 	body Stmt
+	increment Expr
+	label *scanner.Token
 }
 
-func NewWhileLoop(condition Expr, body Stmt) Stmt {
+func NewWhileLoop(condition Expr, body Stmt, increment Expr, label *scanner.Token) Stmt {
 	return &WhileLoop{
 		condition: condition,
 		body: body,
+		increment: increment,
+		label: label,
 	}
 }
 
@@ -182,15 +196,67 @@ func (whileloop *WhileLoop) String() string {
 	return "WhileLoop"}
 
 
+type DoWhile struct {
+	body Stmt
+	keyword *scanner.Token
+	condition Expr
+	until bool
+	label *scanner.Token
+}
+
+func NewDoWhile(body Stmt, keyword *scanner.Token, condition Expr, until bool, label *scanner.Token) Stmt {
+	return &DoWhile{
+		body: body,
+		keyword: keyword,
+		condition: condition,
+		until: until,
+		label: label,
+	}
+}
+
+func (dowhile *DoWhile) accept(visitor StmtVisitor) interface{} {
+	return visitor.visitDoWhileStmt(dowhile)
+}
+
+func (dowhile *DoWhile) String() string {
+	return "DoWhile"}
+
+
+type ForIn struct {
+	keyword *scanner.Token
+	variables []*scanner.Token
+	iterable Expr
+	body Stmt
+	label *scanner.Token
+}
+
+func NewForIn(keyword *scanner.Token, variables []*scanner.Token, iterable Expr, body Stmt, label *scanner.Token) Stmt {
+	return &ForIn{
+		keyword: keyword,
+		variables: variables,
+		iterable: iterable,
+		body: body,
+		label: label,
+	}
+}
+
+func (forin *ForIn) accept(visitor StmtVisitor) interface{} {
+	return visitor.visitForInStmt(forin)
+}
+
+func (forin *ForIn) String() string {
+	return "ForIn"}
+
+
 type BreakCmd struct {
 	keyword *scanner.Token
-	envDepth int
+	label *scanner.Token
 }
 
-func NewBreakCmd(keyword *scanner.Token, envDepth int) Stmt {
+func NewBreakCmd(keyword *scanner.Token, label *scanner.Token) Stmt {
 	return &BreakCmd{
 		keyword: keyword,
-		envDepth: envDepth,
+		label: label,
 	}
 }
 
@@ -204,13 +270,13 @@ func (breakcmd *BreakCmd) String() string {
 
 type ContinueCmd struct {
 	keyword *scanner.Token
-	envDepth int
+	label *scanner.Token
 }
 
-func NewContinueCmd(keyword *scanner.Token, envDepth int) Stmt {
+func NewContinueCmd(keyword *scanner.Token, label *scanner.Token) Stmt {
 	return &ContinueCmd{
 		keyword: keyword,
-		envDepth: envDepth,
+		label: label,
 	}
 }
 
@@ -225,16 +291,24 @@ func (continuecmd *ContinueCmd) String() string {
 type Class struct {
 	name *scanner.Token
 	superclass *Variable
+	traits []*Variable
+	interfaces []*Variable
 	methods []*Function
 	fields []*VarCmd
+	uses []*TraitUse
+	isAbstract bool
 }
 
-func NewClass(name *scanner.Token, superclass *Variable, methods []*Function, fields []*VarCmd) Stmt {
+func NewClass(name *scanner.Token, superclass *Variable, traits []*Variable, interfaces []*Variable, methods []*Function, fields []*VarCmd, uses []*TraitUse, isAbstract bool) Stmt {
 	return &Class{
 		name: name,
 		superclass: superclass,
+		traits: traits,
+		interfaces: interfaces,
 		methods: methods,
 		fields: fields,
+		uses: uses,
+		isAbstract: isAbstract,
 	}
 }
 
@@ -246,3 +320,181 @@ func (class *Class) String() string {
 	return "Class"}
 
 
+type InterfaceCmd struct {
+	name *scanner.Token
+	methods []*Function
+}
+
+func NewInterfaceCmd(name *scanner.Token, methods []*Function) Stmt {
+	return &InterfaceCmd{
+		name: name,
+		methods: methods,
+	}
+}
+
+func (interfacecmd *InterfaceCmd) accept(visitor StmtVisitor) interface{} {
+	return visitor.visitInterfaceCmdStmt(interfacecmd)
+}
+
+func (interfacecmd *InterfaceCmd) String() string {
+	return "InterfaceCmd"}
+
+
+type Match struct {
+	keyword *scanner.Token
+	subject Expr
+	cases []*scanner.Token
+	patterns [][]Pattern
+	guards []Expr
+	bodies []Stmt
+}
+
+func NewMatch(keyword *scanner.Token, subject Expr, cases []*scanner.Token, patterns [][]Pattern, guards []Expr, bodies []Stmt) Stmt {
+	return &Match{
+		keyword: keyword,
+		subject: subject,
+		cases: cases,
+		patterns: patterns,
+		guards: guards,
+		bodies: bodies,
+	}
+}
+
+func (match *Match) accept(visitor StmtVisitor) interface{} {
+	return visitor.visitMatchStmt(match)
+}
+
+func (match *Match) String() string {
+	return "Match"}
+
+
+type ImportCmd struct {
+	keyword *scanner.Token
+	path *scanner.Token
+	name *scanner.Token
+}
+
+func NewImportCmd(keyword *scanner.Token, path *scanner.Token, name *scanner.Token) Stmt {
+	return &ImportCmd{
+		keyword: keyword,
+		path: path,
+		name: name,
+	}
+}
+
+func (importcmd *ImportCmd) accept(visitor StmtVisitor) interface{} {
+	return visitor.visitImportCmdStmt(importcmd)
+}
+
+func (importcmd *ImportCmd) String() string {
+	return "ImportCmd"}
+
+
+type Export struct {
+	keyword *scanner.Token
+	declaration Stmt
+}
+
+func NewExport(keyword *scanner.Token, declaration Stmt) Stmt {
+	return &Export{
+		keyword: keyword,
+		declaration: declaration,
+	}
+}
+
+func (export *Export) accept(visitor StmtVisitor) interface{} {
+	return visitor.visitExportStmt(export)
+}
+
+func (export *Export) String() string {
+	return "Export"}
+
+
+type DeferCmd struct {
+	keyword *scanner.Token
+	expression Expr
+}
+
+func NewDeferCmd(keyword *scanner.Token, expression Expr) Stmt {
+	return &DeferCmd{
+		keyword: keyword,
+		expression: expression,
+	}
+}
+
+func (defercmd *DeferCmd) accept(visitor StmtVisitor) interface{} {
+	return visitor.visitDeferCmdStmt(defercmd)
+}
+
+func (defercmd *DeferCmd) String() string {
+	return "DeferCmd"}
+
+
+type Throw struct {
+	keyword *scanner.Token
+	value Expr
+}
+
+func NewThrow(keyword *scanner.Token, value Expr) Stmt {
+	return &Throw{
+		keyword: keyword,
+		value: value,
+	}
+}
+
+func (throw *Throw) accept(visitor StmtVisitor) interface{} {
+	return visitor.visitThrowStmt(throw)
+}
+
+func (throw *Throw) String() string {
+	return "Throw"}
+
+
+type Try struct {
+	keyword *scanner.Token
+	body []Stmt
+	catchName *scanner.Token
+	catchBody []Stmt
+	hasFinally bool
+	finallyBody []Stmt
+}
+
+func NewTry(keyword *scanner.Token, body []Stmt, catchName *scanner.Token, catchBody []Stmt, hasFinally bool, finallyBody []Stmt) Stmt {
+	return &Try{
+		keyword: keyword,
+		body: body,
+		catchName: catchName,
+		catchBody: catchBody,
+		hasFinally: hasFinally,
+		finallyBody: finallyBody,
+	}
+}
+
+func (try *Try) accept(visitor StmtVisitor) interface{} {
+	return visitor.visitTryStmt(try)
+}
+
+func (try *Try) String() string {
+	return "Try"}
+
+
+type Trait struct {
+	name *scanner.Token
+	methods []*Function
+}
+
+func NewTrait(name *scanner.Token, methods []*Function) Stmt {
+	return &Trait{
+		name: name,
+		methods: methods,
+	}
+}
+
+func (trait *Trait) accept(visitor StmtVisitor) interface{} {
+	return visitor.visitTraitStmt(trait)
+}
+
+func (trait *Trait) String() string {
+	return "Trait"}
+
+
@@ -0,0 +1,220 @@
+package syntax
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// defineStrings defines the strings module. Lengths and indexes count runes,
+// matching how for-in iterates over a string.
+func defineStrings(env *Environment) {
+	env.define("strings", NewNativeModule("strings",
+		NewNativeFunction("len", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return float64(utf8.RuneCountInString(interpreter.stringArgument("len", arguments, 0)))
+		}),
+
+		NewNativeFunction("upper", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return strings.ToUpper(interpreter.stringArgument("upper", arguments, 0))
+		}),
+
+		NewNativeFunction("lower", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return strings.ToLower(interpreter.stringArgument("lower", arguments, 0))
+		}),
+
+		NewNativeFunction("trim", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return strings.TrimSpace(interpreter.stringArgument("trim", arguments, 0))
+		}),
+
+		NewNativeFunction("split", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			parts := strings.Split(interpreter.stringArgument("split", arguments, 0), interpreter.stringArgument("split", arguments, 1))
+
+			elements := make([]interface{}, len(parts))
+			for i, part := range parts {
+				elements[i] = part
+			}
+
+			return NewLoxList(elements)
+		}),
+
+		NewNativeFunction("join", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			separator := interpreter.stringArgument("join", arguments, 1)
+
+			var parts []string
+			interpreter.iterate(interpreter.callToken, arguments[0], func(item interface{}) bool {
+				parts = append(parts, interpreter.stringify(item))
+				return true
+			})
+
+			return strings.Join(parts, separator)
+		}),
+
+		NewNativeFunction("replace", 3, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return strings.ReplaceAll(interpreter.stringArgument("replace", arguments, 0), interpreter.stringArgument("replace", arguments, 1), interpreter.stringArgument("replace", arguments, 2))
+		}),
+
+		NewNativeFunction("indexOf", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			s := interpreter.stringArgument("indexOf", arguments, 0)
+			i := strings.Index(s, interpreter.stringArgument("indexOf", arguments, 1))
+			if i < 0 {
+				return float64(-1)
+			}
+
+			return float64(utf8.RuneCountInString(s[:i]))
+		}),
+
+		NewNativeFunction("contains", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return strings.Contains(interpreter.stringArgument("contains", arguments, 0), interpreter.stringArgument("contains", arguments, 1))
+		}),
+
+		NewNativeFunction("startsWith", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return strings.HasPrefix(interpreter.stringArgument("startsWith", arguments, 0), interpreter.stringArgument("startsWith", arguments, 1))
+		}),
+
+		NewNativeFunction("endsWith", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return strings.HasSuffix(interpreter.stringArgument("endsWith", arguments, 0), interpreter.stringArgument("endsWith", arguments, 1))
+		}),
+
+		NewNativeFunction("substring", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			interpreter.checkArity("substring", arguments, 2, 3)
+			runes := []rune(interpreter.stringArgument("substring", arguments, 0))
+
+			var end interface{}
+			if len(arguments) == 3 {
+				end = arguments[2]
+			}
+
+			from, to := checkSlice(interpreter.callToken, arguments[1], end, len(runes))
+			return string(runes[from:to])
+		}),
+
+		NewNativeFunction("repeat", 2, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			count := checkInteger(interpreter.callToken, arguments[1], "Repeat count")
+			if count < 0 {
+				interpreter.nativeError("Repeat count can't be negative.")
+			}
+
+			return strings.Repeat(interpreter.stringArgument("repeat", arguments, 0), count)
+		}),
+
+		NewNativeFunction("padLeft", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			interpreter.checkArity("padLeft", arguments, 2, 3)
+			return pad(interpreter, "padLeft", arguments, true)
+		}),
+
+		NewNativeFunction("padRight", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			interpreter.checkArity("padRight", arguments, 2, 3)
+			return pad(interpreter, "padRight", arguments, false)
+		}),
+
+		NewNativeFunction("format", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			interpreter.checkArity("format", arguments, 1, 256)
+			return interpreter.format(interpreter.stringArgument("format", arguments, 0), arguments[1:])
+		}),
+
+		NewNativeFunction("runes", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			var elements []interface{}
+			for _, r := range interpreter.stringArgument("runes", arguments, 0) {
+				elements = append(elements, string(r))
+			}
+
+			return NewLoxList(elements)
+		}),
+
+		NewNativeFunction("toNumber", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			n, err := strconv.ParseFloat(strings.TrimSpace(interpreter.stringArgument("toNumber", arguments, 0)), 64)
+			if err != nil {
+				return nil
+			}
+
+			return n
+		}),
+	))
+}
+
+// pad pads a string to a width in runes with an optional fill string.
+func pad(interpreter *Interpreter, native string, arguments []interface{}, left bool) string {
+	s := interpreter.stringArgument(native, arguments, 0)
+	width := checkInteger(interpreter.callToken, arguments[1], "Width")
+
+	fill := " "
+	if len(arguments) == 3 {
+		fill = interpreter.stringArgument(native, arguments, 2)
+		if fill == "" {
+			interpreter.nativeError("Padding of '%s' can't be empty.", native)
+		}
+	}
+
+	missing := width - utf8.RuneCountInString(s)
+	if missing <= 0 {
+		return s
+	}
+
+	var padding strings.Builder
+	for n := 0; n < missing; n += utf8.RuneCountInString(fill) {
+		padding.WriteString(fill)
+	}
+
+	// A multi-rune fill can overshoot the width, so trim it back.
+	runes := []rune(padding.String())[:missing]
+
+	if left {
+		return string(runes) + s
+	}
+
+	return s + string(runes)
+}
+
+// format replaces each {} in template with the next argument and each {n}
+// with the nth argument. {{ and }} produce literal braces.
+func (interpreter *Interpreter) format(template string, arguments []interface{}) string {
+	var out strings.Builder
+	next := 0
+
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if c == '}' {
+			if i+1 < len(template) && template[i+1] == '}' {
+				i++
+			}
+			out.WriteByte('}')
+			continue
+		}
+
+		if c != '{' {
+			out.WriteByte(c)
+			continue
+		}
+
+		if i+1 < len(template) && template[i+1] == '{' {
+			out.WriteByte('{')
+			i++
+			continue
+		}
+
+		end := strings.IndexByte(template[i:], '}')
+		if end < 0 {
+			interpreter.nativeError("Unclosed '{' in format string.")
+		}
+
+		index := next
+		if spec := template[i+1 : i+end]; spec != "" {
+			n, err := strconv.Atoi(spec)
+			if err != nil {
+				interpreter.nativeError("Invalid placeholder '{%s}' in format string.", spec)
+			}
+			index = n
+		} else {
+			next++
+		}
+
+		if index < 0 || index >= len(arguments) {
+			interpreter.nativeError("Format string refers to argument %d but only %d were given.", index, len(arguments))
+		}
+
+		out.WriteString(interpreter.stringify(arguments[index]))
+		i += end
+	}
+
+	return out.String()
+}
@@ -0,0 +1,107 @@
+package syntax
+
+import (
+	"time"
+	_ "time/tzdata"
+)
+
+// monotonicStart is the reference point for time.monotonic(). Durations
+// measured from it use the monotonic clock, so they are unaffected by wall
+// clock changes.
+var monotonicStart = time.Now()
+
+// defineTime defines the time module. Durations are in milliseconds, with
+// fractions carrying sub-millisecond precision.
+func defineTime(env *Environment) {
+	module := NewNativeModule("time",
+		NewNativeFunction("now", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return float64(time.Now().UnixNano()) / float64(time.Millisecond)
+		}),
+
+		NewNativeFunction("nanos", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return float64(time.Now().UnixNano())
+		}),
+
+		NewNativeFunction("monotonic", 0, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			return float64(time.Since(monotonicStart)) / float64(time.Millisecond)
+		}),
+
+		NewNativeFunction("sleep", 1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			time.Sleep(time.Duration(interpreter.numberArgument("sleep", arguments, 0) * float64(time.Millisecond)))
+			return nil
+		}),
+
+		// date() is the current local time; date(year, month, day, [hour,
+		// minute, second, millisecond], [zone]) builds a specific one.
+		NewNativeFunction("date", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			if len(arguments) == 0 {
+				return NewLoxDateTime(time.Now())
+			}
+
+			// Only a string after at least three components is a zone, so the
+			// components are checked before the zone is looked up.
+			components, zone := arguments, ""
+			if len(arguments) > 3 {
+				if name, ok := arguments[len(arguments)-1].(string); ok {
+					components, zone = arguments[:len(arguments)-1], name
+				}
+			}
+			interpreter.checkArity("date", components, 3, 7)
+
+			parts := [7]int{0, 1, 1, 0, 0, 0, 0}
+			for i := range components {
+				parts[i] = checkInteger(interpreter.callToken, components[i], "Date component")
+			}
+
+			location := time.Local
+			if zone != "" {
+				location = interpreter.location(zone)
+			}
+
+			return NewLoxDateTime(time.Date(parts[0], time.Month(parts[1]), parts[2], parts[3], parts[4], parts[5], parts[6]*int(time.Millisecond), location))
+		}),
+
+		NewNativeFunction("fromUnix", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			interpreter.checkArity("fromUnix", arguments, 1, 2)
+			ms := interpreter.numberArgument("fromUnix", arguments, 0)
+
+			t := time.Unix(0, int64(ms*float64(time.Millisecond)))
+			if len(arguments) == 2 {
+				t = t.In(interpreter.location(interpreter.stringArgument("fromUnix", arguments, 1)))
+			}
+
+			return NewLoxDateTime(t)
+		}),
+
+		// parse reads text with a Go reference layout such as time.DATE.
+		// Text without a zone offset is read in the given zone, or UTC.
+		NewNativeFunction("parse", -1, func(interpreter *Interpreter, arguments []interface{}) interface{} {
+			interpreter.checkArity("parse", arguments, 1, 3)
+			text := interpreter.stringArgument("parse", arguments, 0)
+
+			layout := time.RFC3339Nano
+			if len(arguments) >= 2 {
+				layout = interpreter.stringArgument("parse", arguments, 1)
+			}
+
+			location := time.UTC
+			if len(arguments) == 3 {
+				location = interpreter.location(interpreter.stringArgument("parse", arguments, 2))
+			}
+
+			t, err := time.ParseInLocation(layout, text, location)
+			if err != nil {
+				interpreter.nativeError("Could not parse '%s' as a date: %s", text, err.Error())
+			}
+
+			return NewLoxDateTime(t)
+		}),
+	)
+
+	module.define("RFC3339", time.RFC3339)
+	module.define("DATE", "2006-01-02")
+	module.define("TIME", "15:04:05")
+	module.define("DATETIME", "2006-01-02 15:04:05")
+
+	env.define("time", module)
+}